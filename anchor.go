@@ -0,0 +1,64 @@
+package aogo
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"sync"
+)
+
+// AnchorMode selects how SendMessage fills in an empty anchor.
+type AnchorMode int
+
+const (
+	// AnchorNone sends messages without an anchor unless the caller passes one.
+	AnchorNone AnchorMode = iota
+	// AnchorCounter uses a counter per process, starting at 1 and zero-padded to 32 digits.
+	AnchorCounter
+	// AnchorRandom uses 32 random base64url characters.
+	AnchorRandom
+)
+
+// anchors hands out anchors per process. It is safe for concurrent use.
+type anchors struct {
+	mode AnchorMode
+
+	mu       sync.Mutex
+	counters map[string]uint64
+}
+
+func newAnchors(mode AnchorMode) *anchors {
+	return &anchors{mode: mode, counters: make(map[string]uint64)}
+}
+
+// next returns the anchor of the next message to process, or "" if anchors are disabled.
+func (a *anchors) next(process string) (string, error) {
+	if a == nil {
+		return "", nil
+	}
+	switch a.mode {
+	case AnchorCounter:
+		a.mu.Lock()
+		a.counters[process]++
+		n := a.counters[process]
+		a.mu.Unlock()
+		return fmt.Sprintf("%032d", n), nil
+	case AnchorRandom:
+		b := make([]byte, 24)
+		if _, err := rand.Read(b); err != nil {
+			return "", fmt.Errorf("failed to generate anchor: %v", err)
+		}
+		return base64.RawURLEncoding.EncodeToString(b), nil
+	}
+	return "", nil
+}
+
+// reset forgets the counter of process, so its next anchor starts at 1 again.
+func (a *anchors) reset(process string) {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	delete(a.counters, process)
+	a.mu.Unlock()
+}
@@ -0,0 +1,94 @@
+package aogo
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/liteseed/goar/signer"
+	"github.com/liteseed/goar/transaction/data_item"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnchors(t *testing.T) {
+	t.Run("Counter", func(t *testing.T) {
+		a := newAnchors(AnchorCounter)
+		first, err := a.next("a")
+		assert.NoError(t, err)
+		assert.Equal(t, "00000000000000000000000000000001", first)
+		second, _ := a.next("a")
+		assert.Equal(t, "00000000000000000000000000000002", second)
+		other, _ := a.next("b")
+		assert.Equal(t, first, other)
+
+		a.reset("a")
+		again, _ := a.next("a")
+		assert.Equal(t, first, again)
+	})
+	t.Run("Random", func(t *testing.T) {
+		a := newAnchors(AnchorRandom)
+		first, err := a.next("a")
+		assert.NoError(t, err)
+		assert.Len(t, first, 32)
+		second, _ := a.next("a")
+		assert.NotEqual(t, first, second)
+	})
+	t.Run("Disabled", func(t *testing.T) {
+		var a *anchors
+		anchor, err := a.next("a")
+		assert.NoError(t, err)
+		assert.Empty(t, anchor)
+		a.reset("a")
+	})
+	t.Run("Concurrent", func(t *testing.T) {
+		a := newAnchors(AnchorCounter)
+		seen := sync.Map{}
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				anchor, _ := a.next("a")
+				_, dup := seen.LoadOrStore(anchor, true)
+				assert.False(t, dup)
+			}()
+		}
+		wg.Wait()
+	})
+}
+
+func TestAutoAnchor(t *testing.T) {
+	process := testProcess
+	var got []string
+	muServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		item, err := data_item.Decode(b)
+		assert.NoError(t, err)
+		got = append(got, item.Anchor)
+		_, err = w.Write([]byte(`{"id": "mockMessageID"}`))
+		assert.NoError(t, err)
+	}))
+	defer muServer.Close()
+
+	s, err := signer.FromPath("./keys/wallet.json")
+	assert.NoError(t, err)
+
+	ao, err := New(WithMUURL(muServer.URL), WithAutoAnchor(AnchorCounter))
+	assert.NoError(t, err)
+	_, err = ao.SendMessage(process, "", nil, "", s)
+	assert.NoError(t, err)
+	_, err = ao.SendMessage(process, "", nil, "thisSentenceIs32BytesLongTrustMe", s)
+	assert.NoError(t, err)
+	ao.ResetAnchor(process)
+	_, err = ao.SendMessage(process, "", nil, "", s)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"00000000000000000000000000000001",
+		"thisSentenceIs32BytesLongTrustMe",
+		"00000000000000000000000000000001",
+	}, got)
+}
@@ -1,6 +1,13 @@
 package aogo
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
 	"github.com/liteseed/goar/signer"
 	"github.com/liteseed/goar/tag"
 )
@@ -8,61 +15,357 @@ import (
 const (
 	MuUrl     = "https://mu.ao-testnet.xyz"
 	CuUrl     = "https://cu.ao-testnet.xyz"
+	SuUrl     = "https://su-router.ao-testnet.xyz"
 	SCHEDULER = "_GQ33BkPtZrqxA84vM8Zk-N2aO0toNNu_C-l-rawrBA"
 	GATEWAY   = "https://arweave.net"
 
 	SDK = "aogo"
+	// Version is the version of this SDK.
+	Version = "0.1.0"
+
+	// ZeroAddress is the acting address of a dry run that does not name one.
+	ZeroAddress = "0000000000000000000000000000000000000000000"
 )
 
+// AO is a client of the AO units. It is safe for concurrent use by multiple goroutines, including with caching,
+// rate limiting, anchors, circuit breakers and ordered sends enabled, and is meant to be shared: its state is
+// either fixed by New or guarded internally. The exceptions are Close, after which ao must not be used, and
+// the values shared with callers: results served from a cache must not be modified, and the Signer, Observer,
+// Tracer, Middleware and units passed to the options must themselves be safe for concurrent use. Tag lists
+// passed to calls are only read, so one list can be shared between goroutines as long as none modifies it.
 type AO struct {
-	mu MU
-	cu CU
+	mu      MU
+	cu      CU
+	su      SU
+	gateway Gateway
+
+	// compute and messenger replace the HTTP units cu and mu when set by NewWithUnits.
+	compute   ComputeUnit
+	messenger MessengerUnit
+
+	concurrency int
+	middleware  []Middleware
+	// transport adjusts the *http.Transport of every unit client, see applyTransport.
+	transport []func(*http.Transport)
+	// transports are the transports applyTransport created, which Close releases.
+	transports []*http.Transport
+	// background runs the goroutines Close stops.
+	background background
+	tracer     Tracer
+	// userAgent and headers are set on every unit request.
+	userAgent string
+	headers   http.Header
+	// signer signs requests that are not given a signer explicitly.
+	signer *signer.Signer
+	// itemSigner, if set, takes the place of signer for data items; see WithDataItemSigner.
+	itemSigner Signer
+	// spawnUpload, if positive, is the spawn data size above which the data is uploaded, see WithSpawnDataUpload.
+	spawnUpload int64
+	// sends serializes messages per process, see WithOrderedSends.
+	sends *sendQueues
+	// cache holds successful results and dry runs, see WithResultCache.
+	cache *resultCache
+	// dryRuns, if set, holds dry runs in place of cache, see WithDryRunCache.
+	dryRuns *resultCache
+	// schedulers maps a process to the URL SchedulerURL resolved for it.
+	schedulers sync.Map
+	// arns maps an ArNS name to the arnsEntry ResolveProcess resolved it to.
+	arns sync.Map
+	// err records the first invalid option, which New returns.
+	err error
 }
 
+// Client is the core of the AO API. *AO implements it; code that depends on Client can be tested with a double.
+type Client interface {
+	SpawnProcess(module string, data []byte, tags []tag.Tag, s *signer.Signer) (string, error)
+	SendMessage(process string, data string, tags *[]tag.Tag, anchor string, s *signer.Signer) (string, error)
+	LoadResult(process string, message string) (*Response, error)
+	DryRun(message Message) (*Response, error)
+}
+
+var _ Client = (*AO)(nil)
+
 type Message struct {
 	ID     string     `json:"Id"`
 	Target string     `json:"Target"`
 	Owner  string     `json:"Owner"`
+	From   string     `json:"From"`
 	Data   any        `json:"Data"`
 	Tags   *[]tag.Tag `json:"Tags"`
 }
 
-func New(options ...func(*AO)) (*AO, error) {
-	ao := &AO{cu: newCU(CuUrl), mu: newMU(MuUrl)}
+// New returns an AO client configured with the default unit URLs, adjusted by options.
+func New(options ...Option) (*AO, error) {
+	ao := &AO{cu: newCU(CuUrl), mu: newMU(MuUrl), su: newSU(SuUrl), gateway: newGateway(GATEWAY), userAgent: DefaultUserAgent}
 	for _, o := range options {
 		o(ao)
 	}
+	ao.applyHeaders()
+	ao.applyTransport()
+	ao.applyMiddleware()
+	if ao.err != nil {
+		return nil, ao.err
+	}
 	return ao, nil
 }
 
-func WthMU(url string) func(*AO) {
-	return func(ao *AO) {
-		ao.mu = newMU(url)
+// NewWithUnits is like New but sends LoadResult and DryRun to cu, and SendMessage and SpawnProcess to mu,
+// instead of the HTTP units. A nil unit keeps the HTTP default. Options that configure the HTTP units, such as
+// WithCUURL, have no effect on units passed here.
+func NewWithUnits(cu ComputeUnit, mu MessengerUnit, options ...Option) (*AO, error) {
+	ao, err := New(options...)
+	if err != nil {
+		return nil, err
+	}
+	ao.compute = cu
+	ao.messenger = mu
+	return ao, nil
+}
+
+// loadResult loads the result of message through the compute unit of ao, aborting when ctx is done if it is the
+// HTTP CU. params, if any, are added to the query of the CU request. A result reporting an Error is returned as
+// a ProcessError.
+func (ao *AO) loadResult(ctx context.Context, process string, message string, params url.Values) (res *Response, err error) {
+	ctx, span := ao.startSpan(ctx, "LoadResult", UnitCU, process)
+	defer func() { span.End(err) }()
+	key := resultKey(process, message, params)
+	if res, ok := ao.cache.get(key); ok && !cacheBypassed(ctx) {
+		return res, nil
+	}
+	defer func() {
+		// A result without output may not be ready yet, so only complete ones are kept.
+		if err == nil && resultReady(res) {
+			ao.cache.put(key, res)
+		}
+	}()
+	if ao.compute != nil {
+		if len(params) > 0 {
+			return nil, errors.New("query parameters need the HTTP compute unit")
+		}
+		return ao.compute.LoadResult(process, message)
+	}
+	res, err = ao.cu.loadResult(ctx, process, message, params)
+	if err != nil {
+		return nil, err
+	}
+	return processResult(res)
+}
+
+// sendMessage sends a message through the messenger unit of ao. Messages with binary data need the HTTP MU.
+func (ao *AO) sendMessage(ctx context.Context, process string, data []byte, tags *[]tag.Tag, anchor string, s *signer.Signer) (id string, err error) {
+	ctx, span := ao.startSpan(ctx, "SendMessage", UnitMU, process)
+	defer func() { span.End(err) }()
+	res, err := ao.sendMessageResult(ctx, process, data, tags, anchor, s)
+	if err != nil {
+		return "", err
 	}
+	return res.ID, nil
 }
 
-func WthCU(url string) func(*AO) {
-	return func(ao *AO) {
-		ao.cu = newCU(url)
+func (ao *AO) sendMessageResult(ctx context.Context, process string, data []byte, tags *[]tag.Tag, anchor string, s *signer.Signer) (*MessageResult, error) {
+	release, err := ao.sends.acquire(ctx, process)
+	if err != nil {
+		return nil, err
 	}
+	defer release()
+	if ao.messenger != nil {
+		if ao.mu.references != nil {
+			// The tags are copied like the HTTP MU does before the Reference tag is added.
+			var newTags []tag.Tag
+			if tags != nil {
+				newTags = append(newTags, *tags...)
+			}
+			newTags = ao.mu.withReference(process, newTags)
+			tags = &newTags
+		}
+		id, err := ao.messenger.SendMessage(process, string(data), tags, anchor, ao.signerOr(s))
+		if err != nil {
+			return nil, err
+		}
+		res := &MessageResult{ID: id}
+		if tags != nil {
+			res.Reference, _ = FindTag(*tags, "Reference")
+		}
+		return res, nil
+	}
+	return ao.mu.sendMessage(ctx, process, data, tags, anchor, ao.itemSignerOr(s))
+}
+
+// requestContext derives a context from ctx that expires after timeout, or one without a deadline if timeout is zero.
+// Requests that exceed it fail with an error wrapping context.DeadlineExceeded.
+func requestContext(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+	return context.WithCancel(ctx)
 }
 
 // MU Functions
+//
+// The MU functions sign with the signer set by WithSigner or WithSignerBytes when s is nil.
 
 func (ao *AO) SpawnProcess(module string, data []byte, tags []tag.Tag, s *signer.Signer) (string, error) {
-	return ao.mu.SpawnProcess(module, data, tags, s)
+	return ao.SpawnProcessContext(context.Background(), module, data, tags, s)
+}
+
+// SpawnProcessContext is like SpawnProcess but aborts when ctx is done and traces the call under ctx.
+func (ao *AO) SpawnProcessContext(ctx context.Context, module string, data []byte, tags []tag.Tag, s *signer.Signer) (string, error) {
+	res, err := ao.SpawnProcessResultContext(ctx, module, data, tags, s)
+	if err != nil {
+		return "", err
+	}
+	return res.ProcessID, nil
+}
+
+// SpawnProcessResult is like SpawnProcess but also returns the timestamp, block height and assignment the MU
+// reported for the spawn. A MessengerUnit set by NewWithUnits only reports the process ID, so the other fields
+// are left empty.
+func (ao *AO) SpawnProcessResult(module string, data []byte, tags []tag.Tag, s *signer.Signer) (*SpawnResult, error) {
+	return ao.SpawnProcessResultContext(context.Background(), module, data, tags, s)
+}
+
+// SpawnProcessResultContext is like SpawnProcessResult but aborts when ctx is done and traces the call under ctx.
+func (ao *AO) SpawnProcessResultContext(ctx context.Context, module string, data []byte, tags []tag.Tag, s *signer.Signer) (res *SpawnResult, err error) {
+	ctx, span := ao.startSpan(ctx, "SpawnProcess", UnitMU, "")
+	defer func() { span.End(err) }()
+	if ao.spawnUpload > 0 && int64(len(data)) > ao.spawnUpload {
+		if tags, err = ao.uploadSpawnData(ctx, data, tags, s); err != nil {
+			return nil, err
+		}
+		data = nil
+	}
+	if ao.messenger != nil {
+		id, err := ao.messenger.SpawnProcess(module, data, tags, ao.signerOr(s))
+		if err != nil {
+			return nil, err
+		}
+		return &SpawnResult{ProcessID: id}, nil
+	}
+	return ao.mu.spawnProcess(ctx, module, data, tags, ao.itemSignerOr(s))
 }
 
 func (ao *AO) SendMessage(process string, data string, tags *[]tag.Tag, anchor string, s *signer.Signer) (string, error) {
-	return ao.mu.SendMessage(process, data, tags, anchor, s)
+	return ao.sendMessage(context.Background(), process, []byte(data), tags, anchor, s)
+}
+
+// SendMessageContext is like SendMessage but aborts when ctx is done and traces the call under ctx.
+func (ao *AO) SendMessageContext(ctx context.Context, process string, data string, tags *[]tag.Tag, anchor string, s *signer.Signer) (string, error) {
+	return ao.sendMessage(ctx, process, []byte(data), tags, anchor, s)
+}
+
+// SendMessageResult is like SendMessage but also returns the ID of the signed data item computed locally, for
+// correlating logs and checking the ID the MU answered with.
+func (ao *AO) SendMessageResult(process string, data string, tags *[]tag.Tag, anchor string, s *signer.Signer) (res *MessageResult, err error) {
+	ctx, span := ao.startSpan(context.Background(), "SendMessage", UnitMU, process)
+	defer func() { span.End(err) }()
+	return ao.sendMessageResult(ctx, process, []byte(data), tags, anchor, s)
+}
+
+func (ao *AO) SendMessageBytes(process string, data []byte, tags *[]tag.Tag, anchor string, s *signer.Signer) (string, error) {
+	return ao.sendMessage(context.Background(), process, data, tags, anchor, s)
+}
+
+func (ao *AO) Monitor(process string, s *signer.Signer) (string, error) {
+	return ao.mu.Monitor(process, ao.signerOr(s))
+}
+
+func (ao *AO) Unmonitor(process string, s *signer.Signer) error {
+	return ao.mu.Unmonitor(process, ao.signerOr(s))
+}
+
+func (ao *AO) Assign(process string, message string, opts AssignOptions) (string, error) {
+	return ao.mu.Assign(process, message, opts)
+}
+
+// ResetAnchor restarts the automatic anchor counter of process. It has no effect without WithAutoAnchor.
+func (ao *AO) ResetAnchor(process string) {
+	ao.mu.anchors.reset(process)
 }
 
 // CU Functions
 
 func (ao *AO) LoadResult(process string, message string) (*Response, error) {
-	return ao.cu.LoadResult(process, message)
+	return ao.loadResult(context.Background(), process, message, nil)
+}
+
+// LoadResultContext is like LoadResult but aborts when ctx is done and traces the call under ctx.
+func (ao *AO) LoadResultContext(ctx context.Context, process string, message string) (*Response, error) {
+	return ao.loadResult(ctx, process, message, nil)
+}
+
+// ProcessResults reads a page of the results of the messages process has evaluated, for example its latest ten
+// with ResultsQuery{Sort: "DESC", Limit: 10}. A result reporting an Error is returned in the page as it is, not
+// as a ProcessError. It needs the HTTP CU.
+func (ao *AO) ProcessResults(process string, q ResultsQuery) (*ResultsPage, error) {
+	return ao.ProcessResultsContext(context.Background(), process, q)
+}
+
+// ProcessResultsContext is like ProcessResults but aborts when ctx is done and traces the call under ctx.
+func (ao *AO) ProcessResultsContext(ctx context.Context, process string, q ResultsQuery) (page *ResultsPage, err error) {
+	ctx, span := ao.startSpan(ctx, "ProcessResults", UnitCU, process)
+	defer func() { span.End(err) }()
+	if ao.compute != nil {
+		return nil, errors.New("process results need the HTTP compute unit")
+	}
+	return ao.cu.results(ctx, process, q)
 }
 
 func (ao *AO) DryRun(message Message) (*Response, error) {
-	return ao.cu.DryRun(message)
+	return ao.DryRunContext(context.Background(), message)
+}
+
+// DryRunContext is like DryRun but aborts when ctx is done and traces the call under ctx.
+func (ao *AO) DryRunContext(ctx context.Context, message Message) (res *Response, err error) {
+	ctx, span := ao.startSpan(ctx, "DryRun", UnitCU, message.Target)
+	defer func() { span.End(err) }()
+	cache := ao.cache
+	if ao.dryRuns != nil {
+		cache = ao.dryRuns
+	}
+	if key, ok := dryRunKey(message); ok && cache != nil {
+		if res, ok := cache.get(key); ok && !cacheBypassed(ctx) {
+			return res, nil
+		}
+		defer func() {
+			if err == nil && res.Error == "" {
+				cache.put(key, res)
+			}
+		}()
+	}
+	if ao.compute != nil {
+		return ao.compute.DryRun(message)
+	}
+	return ao.cu.dryRun(ctx, message)
+}
+
+// DryRunAs evaluates action on process as if it was sent by from, which lets callers exercise a process' access
+// checks.
+func (ao *AO) DryRunAs(process string, from string, action string, tags []tag.Tag) (*Response, error) {
+	t := append([]tag.Tag{{Name: "Action", Value: action}}, tags...)
+	return ao.DryRun(Message{Target: process, Owner: from, From: from, Tags: &t})
+}
+
+// DryRunSigned is like DryRun but evaluates message as the address of s, the way the signed message would be,
+// unless message.Owner is already set. A nil s falls back to the default signer.
+func (ao *AO) DryRunSigned(message Message, s *signer.Signer) (*Response, error) {
+	if message.Owner == "" {
+		owner, err := ao.Address(s)
+		if err != nil {
+			return nil, err
+		}
+		message.Owner = owner
+	}
+	return ao.DryRun(message)
+}
+
+// SU Functions
+
+func (ao *AO) GetMessages(process string, from string, to string) (*SUPage, error) {
+	return ao.su.GetMessages(process, from, to)
+}
+
+// Gateway Functions
+
+func (ao *AO) GetTransaction(id string) (*Transaction, error) {
+	return ao.gateway.GetTransaction(id)
 }
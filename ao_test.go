@@ -1,15 +1,26 @@
 package aogo
 
 import (
+	"context"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/liteseed/goar/signer"
 	"github.com/liteseed/goar/tag"
 	"github.com/stretchr/testify/assert"
 )
 
+// IDs for tests that need well-formed IDs but do not care about their value.
+const (
+	testProcess = "yugMfaR-u_11GkAuZhqeChPuzoxVYuJW8RnNCIby-D8"
+	testMessage = "3Xh3q5UkwyRn5Nqdtv2u_lE6K4gGH7ZD5nNE8GrCcJs"
+	testModule  = "SBNb1qPQ1TDwpD_mboxm2YllmMLXpWw4U8P9Ipy8Pkc"
+)
+
 func NewAOMock(CUURL, MUURL string) *AO {
 	return &AO{
 		cu: newCU(CUURL),
@@ -49,7 +60,7 @@ func TestSpawnProcess_AO(t *testing.T) {
 		tags := []tag.Tag{{Name: "TestTag", Value: "TestValue"}}
 		s := setupSigner(t)
 
-		id, err := ao.SpawnProcess("testModule", data, tags, s)
+		id, err := ao.SpawnProcess(testModule, data, tags, s)
 		assert.NoError(t, err)
 		assert.Equal(t, "mockProcessID", id)
 	})
@@ -65,7 +76,7 @@ func TestSpawnProcess_AO(t *testing.T) {
 		ao := NewAOMock("", muServer.URL)
 		s := setupSigner(t)
 
-		id, err := ao.SpawnProcess("testModule", nil, nil, s)
+		id, err := ao.SpawnProcess(testModule, nil, nil, s)
 		assert.NoError(t, err)
 		assert.Equal(t, "mockProcessID", id)
 	})
@@ -73,7 +84,7 @@ func TestSpawnProcess_AO(t *testing.T) {
 	t.Run("InvalidSigner", func(t *testing.T) {
 		ao := NewAOMock("", "")
 
-		_, err := ao.SpawnProcess("testModule", []byte("testData"), nil, nil)
+		_, err := ao.SpawnProcess(testModule, []byte("testData"), nil, nil)
 		assert.Error(t, err)
 	})
 
@@ -86,7 +97,7 @@ func TestSpawnProcess_AO(t *testing.T) {
 		ao := NewAOMock("", muServer.URL)
 		s := setupSigner(t)
 
-		_, err := ao.SpawnProcess("testModule", []byte("testData"), nil, s)
+		_, err := ao.SpawnProcess(testModule, []byte("testData"), nil, s)
 		assert.Error(t, err)
 	})
 }
@@ -101,7 +112,7 @@ func TestSendMessage_AO(t *testing.T) {
 		})
 
 		ao := NewAOMock("", muServer.URL)
-		process := "testProcess"
+		process := testProcess
 		data := "testData"
 		tags := &[]tag.Tag{{Name: "TestTag", Value: "TestValue"}}
 		s := setupSigner(t)
@@ -122,7 +133,7 @@ func TestSendMessage_AO(t *testing.T) {
 		ao := NewAOMock("", muServer.URL)
 		s := setupSigner(t)
 
-		id, err := ao.SendMessage("testProcess", "", nil, "", s)
+		id, err := ao.SendMessage(testProcess, "", nil, "", s)
 		assert.NoError(t, err)
 		assert.Equal(t, "mockMessageID", id)
 	})
@@ -130,7 +141,7 @@ func TestSendMessage_AO(t *testing.T) {
 	t.Run("InvalidSigner", func(t *testing.T) {
 		ao := NewAOMock("", "")
 
-		_, err := ao.SendMessage("testProcess", "testData", nil, "", nil)
+		_, err := ao.SendMessage(testProcess, "testData", nil, "", nil)
 		assert.Error(t, err)
 	})
 
@@ -143,7 +154,7 @@ func TestSendMessage_AO(t *testing.T) {
 		ao := NewAOMock("", muServer.URL)
 		s := setupSigner(t)
 
-		_, err := ao.SendMessage("testProcess", "testData", nil, "", s)
+		_, err := ao.SendMessage(testProcess, "testData", nil, "", s)
 		assert.Error(t, err)
 	})
 }
@@ -158,13 +169,13 @@ func TestLoadResult_AO(t *testing.T) {
 		})
 
 		ao := NewAOMock(cuServer.URL, "")
-		process := "testProcess"
-		message := "testMessage"
+		process := testProcess
+		message := testMessage
 
 		resp, err := ao.LoadResult(process, message)
 		assert.NoError(t, err)
 		assert.NotNil(t, resp)
-		assert.Equal(t, 0, resp.GasUsed)
+		assert.Equal(t, Gas(0), resp.GasUsed)
 	})
 
 	t.Run("NonExistentProcessMessage", func(t *testing.T) {
@@ -176,7 +187,7 @@ func TestLoadResult_AO(t *testing.T) {
 		})
 
 		ao := NewAOMock(cuServer.URL, "")
-		_, err := ao.LoadResult("nonExistentProcess", "nonExistentMessage")
+		_, err := ao.LoadResult(testProcess, testMessage)
 		assert.Error(t, err)
 	})
 
@@ -187,7 +198,7 @@ func TestLoadResult_AO(t *testing.T) {
 		})
 
 		ao := NewAOMock(cuServer.URL, "")
-		_, err := ao.LoadResult("testProcess", "testMessage")
+		_, err := ao.LoadResult(testProcess, testMessage)
 		assert.Error(t, err)
 	})
 }
@@ -213,7 +224,7 @@ func TestDryRun_AO(t *testing.T) {
 		resp, err := ao.DryRun(message)
 		assert.NoError(t, err)
 		assert.NotNil(t, resp)
-		assert.Equal(t, 0, resp.GasUsed)
+		assert.Equal(t, Gas(0), resp.GasUsed)
 	})
 
 	t.Run("EmptyMessageData", func(t *testing.T) {
@@ -236,7 +247,7 @@ func TestDryRun_AO(t *testing.T) {
 		resp, err := ao.DryRun(message)
 		assert.NoError(t, err)
 		assert.NotNil(t, resp)
-		assert.Equal(t, 0, resp.GasUsed)
+		assert.Equal(t, Gas(0), resp.GasUsed)
 	})
 
 	t.Run("InvalidMessageFormat", func(t *testing.T) {
@@ -279,3 +290,123 @@ func TestDryRun_AO(t *testing.T) {
 		assert.Error(t, err)
 	})
 }
+
+type stubCU struct {
+	results map[string]*Response
+	dryRuns []Message
+}
+
+func (c *stubCU) LoadResult(process string, message string) (*Response, error) {
+	res, ok := c.results[message]
+	if !ok {
+		return nil, ErrEmptyResult
+	}
+	return res, nil
+}
+
+func (c *stubCU) DryRun(message Message) (*Response, error) {
+	c.dryRuns = append(c.dryRuns, message)
+	return &Response{Messages: []ResultMessage{{Data: "dry"}}}, nil
+}
+
+type stubMU struct {
+	sent []string
+}
+
+func (m *stubMU) SendMessage(process string, data string, tags *[]tag.Tag, anchor string, s *signer.Signer) (string, error) {
+	m.sent = append(m.sent, data)
+	return "message", nil
+}
+
+func (m *stubMU) SpawnProcess(module string, data []byte, tags []tag.Tag, s *signer.Signer) (string, error) {
+	return "process", nil
+}
+
+func TestNewWithUnits(t *testing.T) {
+	cu := &stubCU{results: map[string]*Response{testMessage: {Messages: []ResultMessage{{Data: "done"}}, GasUsed: 7}}}
+	mu := &stubMU{}
+	ao, err := NewWithUnits(cu, mu)
+	assert.NoError(t, err)
+
+	id, err := ao.SpawnProcess(testModule, nil, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "process", id)
+	spawn, err := ao.SpawnProcessResult(testModule, nil, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, &SpawnResult{ProcessID: "process"}, spawn)
+
+	id, err = ao.Process(testProcess).Send("hello", nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "message", id)
+	assert.Equal(t, []string{"hello"}, mu.sent)
+
+	res, err := ao.LoadResult(testProcess, testMessage)
+	assert.NoError(t, err)
+	assert.Equal(t, Gas(7), res.GasUsed)
+
+	res, err = ao.WaitForResult(context.Background(), testProcess, testMessage, WaitOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, Gas(7), res.GasUsed)
+
+	results, errs := ao.LoadResults(context.Background(), testProcess, []string{testMessage, testProcess}, 0)
+	assert.Equal(t, Gas(7), results[0].GasUsed)
+	assert.ErrorIs(t, errs[1], ErrEmptyResult)
+
+	out, err := DryRunDecode[string](ao, Message{Target: testProcess}, nil)
+	assert.Error(t, err)
+	assert.Empty(t, out)
+	assert.Len(t, cu.dryRuns, 1)
+}
+
+// TestConcurrentUse shares one client with every stateful option between goroutines. It is meant to be run with
+// -race.
+func TestConcurrentUse(t *testing.T) {
+	var mu sync.Mutex
+	anchors := map[string]bool{}
+	muServer := setupMU(t, func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		item, err := DecodeDataItem(b)
+		assert.NoError(t, err)
+		mu.Lock()
+		// Anchors are counted per process.
+		key := item.Target + "/" + item.Anchor
+		assert.False(t, anchors[key], "anchor %s reused", key)
+		anchors[key] = true
+		mu.Unlock()
+		_, err = w.Write([]byte(`{"id": "` + item.ID + `"}`))
+		assert.NoError(t, err)
+	})
+	cuServer := setupCU(t, func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"Messages": [], "Spawns": [], "Outputs": ["ok"], "GasUsed": 0}`))
+		assert.NoError(t, err)
+	})
+
+	ao, err := New(WithMUURL(muServer.URL), WithCUURLs([]string{cuServer.URL, cuServer.URL}), WithSigner(setupSigner(t)),
+		WithResultCache(4, time.Minute), WithDryRunCache(time.Minute), WithRateLimit(1000, 100),
+		WithAutoAnchor(AnchorCounter), WithCircuitBreaker(5, time.Second), WithOrderedSends())
+	assert.NoError(t, err)
+	defer ao.Close()
+
+	shared := []tag.Tag{{Name: "Action", Value: "Eval"}}
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			process := []string{testProcess, testModule}[i%2]
+			for range 5 {
+				var stats CallStats
+				_, err := ao.SendMessageContext(WithCallStats(context.Background(), &stats), process, "data", &shared, "", nil)
+				assert.NoError(t, err)
+				_, err = ao.LoadResult(process, testMessage)
+				assert.NoError(t, err)
+				_, err = ao.DryRun(Message{Target: process, Tags: &shared})
+				assert.NoError(t, err)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, []tag.Tag{{Name: "Action", Value: "Eval"}}, shared, "shared tags are left as they were")
+	assert.Len(t, anchors, 40)
+}
@@ -0,0 +1,70 @@
+// Package aootel traces AO calls with OpenTelemetry.
+//
+//	ao, err := aogo.New(aootel.WithTracerProvider(otel.GetTracerProvider()))
+//
+// Every SpawnProcess, SendMessage, LoadResult and DryRun call, including those made by helpers such as
+// WaitForResult or Transfer, gets a client span named after the call, nested under the span of the context
+// passed to the Context variants of these methods.
+//
+// aootel is a module of its own, github.com/liteseed/aogo/aootel, so that programs using aogo without tracing do
+// not depend on OpenTelemetry.
+package aootel
+
+import (
+	"context"
+
+	"github.com/liteseed/aogo"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope of the spans.
+const ScopeName = "github.com/liteseed/aogo"
+
+// Span attributes.
+const (
+	// UnitKey is the AO unit called: cu or mu.
+	UnitKey = attribute.Key("ao.unit")
+	// ProcessKey is the process the call targets. Spawns have none.
+	ProcessKey = attribute.Key("ao.process")
+	// StatusCodeKey is the HTTP status of the last request made by the call.
+	StatusCodeKey = attribute.Key("http.response.status_code")
+)
+
+// WithTracerProvider traces AO calls with spans from tp. A nil tp disables tracing.
+func WithTracerProvider(tp trace.TracerProvider) aogo.Option {
+	if tp == nil {
+		return aogo.WithTracer(nil)
+	}
+	return aogo.WithTracer(tracer{tp.Tracer(ScopeName, trace.WithInstrumentationVersion(aogo.Version))})
+}
+
+type tracer struct {
+	tracer trace.Tracer
+}
+
+func (t tracer) Start(ctx context.Context, operation string, unit aogo.Unit, process string) (context.Context, aogo.Span) {
+	attrs := []attribute.KeyValue{UnitKey.String(string(unit))}
+	if process != "" {
+		attrs = append(attrs, ProcessKey.String(process))
+	}
+	ctx, s := t.tracer.Start(ctx, "aogo."+operation, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+	return ctx, span{s}
+}
+
+type span struct {
+	span trace.Span
+}
+
+func (s span) SetStatusCode(code int) {
+	s.span.SetAttributes(StatusCodeKey.Int(code))
+}
+
+func (s span) End(err error) {
+	if err != nil {
+		s.span.RecordError(err)
+		s.span.SetStatus(codes.Error, err.Error())
+	}
+	s.span.End()
+}
@@ -0,0 +1,110 @@
+package aootel
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/liteseed/aogo"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/embedded"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+type recorder struct {
+	embedded.TracerProvider
+	spans []*recordedSpan
+}
+
+func (r *recorder) Tracer(string, ...trace.TracerOption) trace.Tracer {
+	return recordingTracer{r: r}
+}
+
+type recordingTracer struct {
+	embedded.Tracer
+	r *recorder
+}
+
+func (t recordingTracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	cfg := trace.NewSpanStartConfig(opts...)
+	s := &recordedSpan{name: name, kind: cfg.SpanKind(), attrs: cfg.Attributes(), parent: trace.SpanFromContext(ctx)}
+	t.r.spans = append(t.r.spans, s)
+	return trace.ContextWithSpan(ctx, s), s
+}
+
+type recordedSpan struct {
+	noop.Span
+	name   string
+	kind   trace.SpanKind
+	attrs  []attribute.KeyValue
+	parent trace.Span
+	errs   []error
+	status codes.Code
+	ended  bool
+}
+
+func (s *recordedSpan) SetAttributes(kv ...attribute.KeyValue)        { s.attrs = append(s.attrs, kv...) }
+func (s *recordedSpan) RecordError(err error, _ ...trace.EventOption) { s.errs = append(s.errs, err) }
+func (s *recordedSpan) SetStatus(code codes.Code, _ string)           { s.status = code }
+func (s *recordedSpan) End(...trace.SpanEndOption)                    { s.ended = true }
+
+const (
+	process = "yugMfaR-u_11GkAuZhqeChPuzoxVYuJW8RnNCIby-D8"
+	message = "3Xh3q5UkwyRn5Nqdtv2u_lE6K4gGH7ZD5nNE8GrCcJs"
+)
+
+func TestWithTracerProvider(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, err := w.Write([]byte(`{"Messages": []}`))
+		assert.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	tp := &recorder{}
+	ao, err := aogo.New(aogo.WithCUURL(srv.URL), WithTracerProvider(tp))
+	assert.NoError(t, err)
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "request")
+	_, err = ao.LoadResultContext(ctx, process, message)
+	assert.NoError(t, err)
+	_, err = ao.DryRunContext(ctx, aogo.Message{Target: process})
+	assert.Error(t, err)
+
+	assert.Len(t, tp.spans, 3)
+	load, dryRun := tp.spans[1], tp.spans[2]
+
+	assert.Equal(t, "aogo.LoadResult", load.name)
+	assert.Equal(t, trace.SpanKindClient, load.kind)
+	assert.Same(t, parent, load.parent)
+	assert.Equal(t, []attribute.KeyValue{UnitKey.String("cu"), ProcessKey.String(process), StatusCodeKey.Int(200)}, load.attrs)
+	assert.Equal(t, codes.Unset, load.status)
+	assert.True(t, load.ended)
+
+	assert.Equal(t, "aogo.DryRun", dryRun.name)
+	assert.Same(t, parent, dryRun.parent)
+	assert.Contains(t, dryRun.attrs, StatusCodeKey.Int(400))
+	assert.Equal(t, codes.Error, dryRun.status)
+	assert.Equal(t, []error{err}, dryRun.errs)
+	assert.True(t, dryRun.ended)
+}
+
+func TestWithTracerProviderNil(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"Messages": []}`))
+		assert.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	ao, err := aogo.New(aogo.WithCUURL(srv.URL), WithTracerProvider(nil))
+	assert.NoError(t, err)
+	_, err = ao.LoadResult(process, message)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,24 @@
+module github.com/liteseed/aogo/aootel
+
+go 1.22.1
+
+require (
+	github.com/liteseed/aogo v0.0.0-00010101000000-000000000000
+	github.com/stretchr/testify v1.9.0
+	go.opentelemetry.io/otel v1.28.0
+	go.opentelemetry.io/otel/trace v1.28.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.2-0.20180830191138-d8f796af33cc // indirect
+	github.com/everFinance/gojwk v1.0.0 // indirect
+	github.com/golang/snappy v0.0.5-0.20220116011046-fa5810519dcb // indirect
+	github.com/linkedin/goavro/v2 v2.13.0 // indirect
+	github.com/liteseed/goar v0.3.0 // indirect
+	github.com/pmezard/go-difflib v1.0.1-0.20181226105442-5d4384ee4fb2 // indirect
+	golang.org/x/time v0.5.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+// aootel is developed against the aogo in the parent directory.
+replace github.com/liteseed/aogo => ../
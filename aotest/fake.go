@@ -0,0 +1,181 @@
+// Package aotest provides an in-memory AO for tests.
+//
+// FakeAO implements aogo.Client as well as aogo.ComputeUnit and aogo.MessengerUnit, so it can stand in for
+// an *aogo.AO directly or back one built with aogo.NewWithUnits:
+//
+//	fake := aotest.New()
+//	ao, _ := aogo.NewWithUnits(fake, fake)
+package aotest
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/liteseed/aogo"
+	"github.com/liteseed/goar/signer"
+	"github.com/liteseed/goar/tag"
+)
+
+// SentMessage is a message recorded by FakeAO.SendMessage.
+type SentMessage struct {
+	ID      string
+	Process string
+	Data    string
+	Tags    []tag.Tag
+	Anchor  string
+	// Owner is the address of the signer, or "" if none was given.
+	Owner string
+}
+
+// SpawnedProcess is a process recorded by FakeAO.SpawnProcess.
+type SpawnedProcess struct {
+	ID     string
+	Module string
+	Data   []byte
+	Tags   []tag.Tag
+	Owner  string
+}
+
+// FakeAO records spawns and messages in memory and answers LoadResult and DryRun with canned responses.
+// It is safe for concurrent use.
+type FakeAO struct {
+	mu       sync.Mutex
+	next     int
+	spawned  []SpawnedProcess
+	sent     []SentMessage
+	results  map[string]*aogo.Response
+	dryRuns  map[string]*aogo.Response
+	dryRunIn []aogo.Message
+}
+
+var (
+	_ aogo.Client        = (*FakeAO)(nil)
+	_ aogo.ComputeUnit   = (*FakeAO)(nil)
+	_ aogo.MessengerUnit = (*FakeAO)(nil)
+)
+
+// New returns an empty FakeAO.
+func New() *FakeAO {
+	return &FakeAO{results: make(map[string]*aogo.Response), dryRuns: make(map[string]*aogo.Response)}
+}
+
+// SpawnProcess records the spawn and returns a new, well-formed process ID.
+func (f *FakeAO) SpawnProcess(module string, data []byte, tags []tag.Tag, s *signer.Signer) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	id := f.newID()
+	f.spawned = append(f.spawned, SpawnedProcess{ID: id, Module: module, Data: append([]byte{}, data...), Tags: append([]tag.Tag{}, tags...), Owner: owner(s)})
+	return id, nil
+}
+
+// SendMessage records the message and returns a new, well-formed message ID. tags is left untouched.
+func (f *FakeAO) SendMessage(process string, data string, tags *[]tag.Tag, anchor string, s *signer.Signer) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	id := f.newID()
+	var t []tag.Tag
+	if tags != nil {
+		t = append(t, *tags...)
+	}
+	f.sent = append(f.sent, SentMessage{ID: id, Process: process, Data: data, Tags: t, Anchor: anchor, Owner: owner(s)})
+	return id, nil
+}
+
+// LoadResult returns the response set with SetResult. Like the CU client, a response with an Error is
+// returned as an *aogo.ProcessError. Unknown messages fail with an error wrapping aogo.ErrEmptyResult.
+func (f *FakeAO) LoadResult(process string, message string) (*aogo.Response, error) {
+	f.mu.Lock()
+	res, ok := f.results[process+"/"+message]
+	f.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("aotest: no result for message %s of process %s: %w", message, process, aogo.ErrEmptyResult)
+	}
+	return answer(res)
+}
+
+// DryRun records message and returns the response set with SetDryRun for its target and Action tag, falling
+// back to the one set for its target with an empty action. Unknown dry runs fail with an error wrapping
+// aogo.ErrEmptyResult.
+func (f *FakeAO) DryRun(message aogo.Message) (*aogo.Response, error) {
+	var action string
+	if message.Tags != nil {
+		action, _ = aogo.FindTag(*message.Tags, "Action")
+	}
+	f.mu.Lock()
+	f.dryRunIn = append(f.dryRunIn, message)
+	res, ok := f.dryRuns[message.Target+"/"+action]
+	if !ok {
+		res, ok = f.dryRuns[message.Target+"/"]
+	}
+	f.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("aotest: no dry run for action %q of process %s: %w", action, message.Target, aogo.ErrEmptyResult)
+	}
+	return answer(res)
+}
+
+// SetResult makes LoadResult return res for message on process.
+func (f *FakeAO) SetResult(process string, message string, res *aogo.Response) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.results[process+"/"+message] = res
+}
+
+// SetDryRun makes DryRun return res for messages to process with the Action tag action. An empty action
+// matches any dry run to process without a more specific response.
+func (f *FakeAO) SetDryRun(process string, action string, res *aogo.Response) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.dryRuns[process+"/"+action] = res
+}
+
+// SentMessages returns the messages sent to process, in order.
+func (f *FakeAO) SentMessages(process string) []SentMessage {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var sent []SentMessage
+	for _, m := range f.sent {
+		if m.Process == process {
+			sent = append(sent, m)
+		}
+	}
+	return sent
+}
+
+// Spawned returns the spawned processes, in order.
+func (f *FakeAO) Spawned() []SpawnedProcess {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]SpawnedProcess{}, f.spawned...)
+}
+
+// DryRuns returns the messages passed to DryRun, in order.
+func (f *FakeAO) DryRuns() []aogo.Message {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]aogo.Message{}, f.dryRunIn...)
+}
+
+// newID returns a deterministic 43 character ID. f.mu must be held.
+func (f *FakeAO) newID() string {
+	f.next++
+	sum := sha256.Sum256([]byte("aotest/" + strconv.Itoa(f.next)))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func answer(res *aogo.Response) (*aogo.Response, error) {
+	if res.Error != "" {
+		return nil, &aogo.ProcessError{Message: res.Error, GasUsed: res.GasUsed}
+	}
+	return res, nil
+}
+
+func owner(s *signer.Signer) string {
+	if s == nil {
+		return ""
+	}
+	return s.Address
+}
@@ -0,0 +1,53 @@
+package aotest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/liteseed/aogo"
+	"github.com/liteseed/goar/tag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeAO(t *testing.T) {
+	fake := New()
+	ao, err := aogo.NewWithUnits(fake, fake)
+	assert.NoError(t, err)
+
+	process, err := ao.SpawnProcess("module", []byte("code"), []tag.Tag{{Name: "Name", Value: "test"}}, nil)
+	assert.NoError(t, err)
+	assert.Len(t, process, 43)
+	assert.Equal(t, []SpawnedProcess{{ID: process, Module: "module", Data: []byte("code"), Tags: []tag.Tag{{Name: "Name", Value: "test"}}}}, fake.Spawned())
+
+	tags := []tag.Tag{{Name: "Action", Value: "Eval"}}
+	message, err := ao.SendMessage(process, "1 + 1", &tags, "", nil)
+	assert.NoError(t, err)
+	assert.NotEqual(t, process, message)
+	assert.Equal(t, []SentMessage{{ID: message, Process: process, Data: "1 + 1", Tags: tags}}, fake.SentMessages(process))
+	assert.Empty(t, fake.SentMessages("other"))
+
+	_, err = ao.LoadResult(process, message)
+	assert.ErrorIs(t, err, aogo.ErrEmptyResult)
+
+	fake.SetResult(process, message, &aogo.Response{Messages: []aogo.ResultMessage{{Data: "2"}}})
+	res, err := ao.WaitForResult(context.Background(), process, message, aogo.WaitOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "2", res.Messages[0].Data)
+
+	fake.SetResult(process, "failed", &aogo.Response{Error: "boom", GasUsed: 3})
+	_, err = ao.LoadResult(process, "failed")
+	assert.True(t, aogo.IsProcessError(err))
+
+	fake.SetDryRun(process, "Info", &aogo.Response{Messages: []aogo.ResultMessage{{Tags: []tag.Tag{{Name: "Name", Value: "Token"}}}}})
+	fake.SetDryRun(process, "", &aogo.Response{Messages: []aogo.ResultMessage{{Data: "100"}}})
+	info, err := ao.Info(process)
+	assert.NoError(t, err)
+	assert.Equal(t, "Token", info["Name"])
+	balance, err := ao.Balance(process, "address")
+	assert.NoError(t, err)
+	assert.Equal(t, "100", balance)
+	assert.Len(t, fake.DryRuns(), 2)
+
+	_, err = ao.DryRun(aogo.Message{Target: "other"})
+	assert.ErrorIs(t, err, aogo.ErrEmptyResult)
+}
@@ -0,0 +1,92 @@
+package aogo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultArNSTTL is how long ResolveProcess caches a name whose record does not set a TTL.
+const DefaultArNSTTL = time.Hour
+
+type arnsEntry struct {
+	id      string
+	expires time.Time
+}
+
+type arnsRecord struct {
+	TxID       string `json:"txId"`
+	TTLSeconds int64  `json:"ttlSeconds"`
+}
+
+// ResolveProcess returns the process ID name refers to. name is an ArNS name such as "myapp", an ar:// URL
+// such as "ar://myapp", or a process ID, which is returned as is. Names are resolved through the ArNS resolver
+// of the gateway and cached for the TTL of their record, or DefaultArNSTTL if it has none.
+func (ao *AO) ResolveProcess(name string) (string, error) {
+	return ao.ResolveProcessContext(context.Background(), name)
+}
+
+// ResolveProcessContext is like ResolveProcess but aborts the lookup when ctx is done.
+func (ao *AO) ResolveProcessContext(ctx context.Context, name string) (string, error) {
+	name = strings.TrimSuffix(strings.TrimPrefix(name, "ar://"), "/")
+	if validateID("process", name) == nil {
+		return name, nil
+	}
+	name = strings.ToLower(name)
+	if err := validateArNSName(name); err != nil {
+		return "", err
+	}
+	if e, ok := ao.arns.Load(name); ok && time.Now().Before(e.(arnsEntry).expires) {
+		return e.(arnsEntry).id, nil
+	}
+
+	record, err := ao.gateway.resolveName(ctx, name)
+	if err != nil {
+		return "", fmt.Errorf("resolve %s: %w", name, err)
+	}
+	if err := validateID("process", record.TxID); err != nil {
+		return "", fmt.Errorf("resolve %s: %w", name, err)
+	}
+	ttl := time.Duration(record.TTLSeconds) * time.Second
+	if ttl <= 0 {
+		ttl = DefaultArNSTTL
+	}
+	ao.arns.Store(name, arnsEntry{id: record.TxID, expires: time.Now().Add(ttl)})
+	return record.TxID, nil
+}
+
+// validateArNSName checks that name, already lowercased, is a valid ArNS name or undername, such as
+// "docs_myapp", so a typo fails before any request.
+func validateArNSName(name string) error {
+	if name == "" || len(name) > 63 {
+		return fmt.Errorf("%w: ArNS name %q must be 1 to 63 characters", ErrInvalidID, name)
+	}
+	for _, c := range name {
+		if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+			return fmt.Errorf("%w: ArNS name %q contains %q", ErrInvalidID, name, c)
+		}
+	}
+	return nil
+}
+
+// resolveName looks up the ArNS record of name through the gateway's resolver.
+func (g *Gateway) resolveName(ctx context.Context, name string) (*arnsRecord, error) {
+	resp, cancel, err := g.get(ctx, "/ar-io/resolver/"+url.PathEscape(name), "application/json")
+	if err != nil {
+		return nil, fmt.Errorf("arns: %w", err)
+	}
+	defer cancel()
+	defer resp.Body.Close()
+	b, err := readBody(resp.Body, g.maxResponseBytes)
+	if err != nil {
+		return nil, err
+	}
+	var record arnsRecord
+	if err := json.Unmarshal(b, &record); err != nil {
+		return nil, unmarshalError("ArNS record", err, b, g.maxErrorBody)
+	}
+	return &record, nil
+}
@@ -0,0 +1,45 @@
+package aogo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveProcess(t *testing.T) {
+	lookups := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lookups++
+		switch r.URL.Path {
+		case "/ar-io/resolver/myapp":
+			_, err := w.Write([]byte(`{"txId": "` + testProcess + `", "ttlSeconds": 3600, "processId": "` + testModule + `"}`))
+			assert.NoError(t, err)
+		default:
+			http.Error(w, `{"error": "Record not found"}`, http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	ao := &AO{gateway: newGateway(srv.URL)}
+	for _, name := range []string{"myapp", "ar://myapp", "MyApp"} {
+		id, err := ao.ResolveProcess(name)
+		assert.NoError(t, err, name)
+		assert.Equal(t, testProcess, id, name)
+	}
+	assert.Equal(t, 1, lookups, "resolutions are cached")
+
+	id, err := ao.ResolveProcess("ar://" + testMessage)
+	assert.NoError(t, err)
+	assert.Equal(t, testMessage, id)
+	assert.Equal(t, 1, lookups, "IDs are not looked up")
+
+	_, err = ao.ResolveProcess("missing")
+	var aoErr *AOError
+	assert.ErrorAs(t, err, &aoErr)
+	assert.Equal(t, http.StatusNotFound, aoErr.StatusCode)
+
+	_, err = ao.ResolveProcess("my app")
+	assert.ErrorIs(t, err, ErrInvalidID)
+}
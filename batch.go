@@ -0,0 +1,98 @@
+package aogo
+
+import (
+	"context"
+	"sync"
+
+	"github.com/liteseed/goar/signer"
+	"github.com/liteseed/goar/tag"
+)
+
+// DefaultConcurrency is the number of requests a batch call keeps in flight unless set with WithConcurrency.
+const DefaultConcurrency = 8
+
+// MessageInput is a single message of a SendMessages batch.
+type MessageInput struct {
+	Data   string
+	Tags   []tag.Tag
+	Anchor string
+}
+
+// SendResult is the outcome of one message of a SendMessages batch.
+type SendResult struct {
+	ID  string
+	Err error
+}
+
+// SendMessages signs and sends msgs to process using a bounded pool of workers.
+// The returned results are aligned with msgs; a failed message does not stop the rest of the batch.
+func (ao *AO) SendMessages(process string, msgs []MessageInput, s *signer.Signer) []SendResult {
+	results := make([]SendResult, len(msgs))
+	ao.forEach(len(msgs), func(i int) {
+		tags := append([]tag.Tag{}, msgs[i].Tags...)
+		id, err := ao.SendMessage(process, msgs[i].Data, &tags, msgs[i].Anchor, s)
+		results[i] = SendResult{ID: id, Err: err}
+	})
+	return results
+}
+
+// LoadResults loads the results of messages on process with at most concurrency requests in flight;
+// zero or less uses the client's concurrency. Both slices are aligned with messages: a failed load sets only
+// its own error. Once ctx is done, loads in flight are aborted and the remaining ones fail with ctx.Err().
+func (ao *AO) LoadResults(ctx context.Context, process string, messages []string, concurrency int) ([]*Response, []error) {
+	results := make([]*Response, len(messages))
+	errs := make([]error, len(messages))
+	ao.forEachLimit(concurrency, len(messages), func(i int) {
+		if err := ctx.Err(); err != nil {
+			errs[i] = err
+			return
+		}
+		results[i], errs[i] = ao.loadResult(ctx, process, messages[i], nil)
+	})
+	return results, errs
+}
+
+// DryRuns evaluates msgs as dry runs with at most concurrency requests in flight; zero or less uses the client's
+// concurrency. Like LoadResults, both slices are aligned with msgs, a failed dry run sets only its own error,
+// and once ctx is done, dry runs in flight are aborted and the remaining ones fail with ctx.Err().
+func (ao *AO) DryRuns(ctx context.Context, msgs []Message, concurrency int) ([]*Response, []error) {
+	results := make([]*Response, len(msgs))
+	errs := make([]error, len(msgs))
+	ao.forEachLimit(concurrency, len(msgs), func(i int) {
+		if err := ctx.Err(); err != nil {
+			errs[i] = err
+			return
+		}
+		results[i], errs[i] = ao.DryRunContext(ctx, msgs[i])
+	})
+	return results, errs
+}
+
+// forEach calls fn for every index in [0, n) with at most ao.concurrency calls running at once.
+func (ao *AO) forEach(n int, fn func(i int)) {
+	ao.forEachLimit(0, n, fn)
+}
+
+// forEachLimit is like forEach but runs at most concurrency calls at once, or ao.concurrency if it is zero or less.
+func (ao *AO) forEachLimit(concurrency int, n int, fn func(i int)) {
+	if concurrency <= 0 {
+		concurrency = ao.concurrency
+	}
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			fn(i)
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,191 @@
+package aogo
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/liteseed/goar/signer"
+	"github.com/liteseed/goar/transaction/data_item"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSendMessages(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			m := maxInFlight.Load()
+			if n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+
+		b, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		item, err := data_item.Decode(b)
+		assert.NoError(t, err)
+		data, err := base64.RawURLEncoding.DecodeString(item.Data)
+		assert.NoError(t, err)
+		if string(data) == "fail" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, err = w.Write([]byte(`{"id": "` + string(data) + `"}`))
+		assert.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	ao, err := New(WithMUURL(srv.URL), WithConcurrency(2))
+	assert.NoError(t, err)
+
+	s, err := signer.FromPath("./keys/wallet.json")
+	assert.NoError(t, err)
+
+	msgs := []MessageInput{{Data: "a"}, {Data: "fail"}, {Data: "c"}, {Data: "d"}}
+	results := ao.SendMessages(testProcess, msgs, s)
+	assert.Len(t, results, 4)
+	assert.Equal(t, "a", results[0].ID)
+	assert.NoError(t, results[0].Err)
+	assert.Error(t, results[1].Err)
+	assert.Equal(t, "c", results[2].ID)
+	assert.Equal(t, "d", results[3].ID)
+	assert.LessOrEqual(t, maxInFlight.Load(), int32(2))
+}
+
+func TestLoadResults(t *testing.T) {
+	t.Run("Ordered", func(t *testing.T) {
+		var inFlight, maxInFlight atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			n := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for {
+				m := maxInFlight.Load()
+				if n <= m || maxInFlight.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+
+			last := r.URL.Path[len(r.URL.Path)-1:]
+			switch last {
+			case "1":
+				w.WriteHeader(http.StatusNotFound)
+			case "2":
+				_, err := w.Write([]byte(`{"Error": "boom"}`))
+				assert.NoError(t, err)
+			default:
+				_, err := w.Write([]byte(`{"GasUsed": ` + last + `}`))
+				assert.NoError(t, err)
+			}
+		}))
+		defer srv.Close()
+
+		ao, err := New(WithCUURL(srv.URL), WithCURetry(NoRetry))
+		assert.NoError(t, err)
+
+		var messages []string
+		for i := 0; i < 6; i++ {
+			messages = append(messages, strings.Repeat("m", 42)+strconv.Itoa(i))
+		}
+		results, errs := ao.LoadResults(context.Background(), testProcess, messages, 2)
+		assert.Len(t, results, 6)
+		assert.Len(t, errs, 6)
+		for i := range messages {
+			switch i {
+			case 1:
+				assert.ErrorIs(t, errs[i], ErrProcessNotFound)
+				assert.Nil(t, results[i])
+			case 2:
+				assert.True(t, IsProcessError(errs[i]))
+				assert.Nil(t, results[i])
+			default:
+				assert.NoError(t, errs[i])
+				assert.Equal(t, Gas(i), results[i].GasUsed)
+			}
+		}
+		assert.LessOrEqual(t, maxInFlight.Load(), int32(2))
+	})
+	t.Run("Canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cancel()
+			<-r.Context().Done()
+		}))
+		defer srv.Close()
+
+		ao, err := New(WithCUURL(srv.URL))
+		assert.NoError(t, err)
+
+		messages := []string{testMessage, testMessage, testMessage, testMessage}
+		results, errs := ao.LoadResults(ctx, testProcess, messages, 1)
+		for i := range messages {
+			assert.Nil(t, results[i])
+			assert.ErrorIs(t, errs[i], context.Canceled)
+		}
+	})
+}
+
+func TestDryRuns(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			m := maxInFlight.Load()
+			if n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+
+		var msg Message
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&msg))
+		switch msg.Data {
+		case "1":
+			_, err := w.Write([]byte(`{"Error": "boom"}`))
+			assert.NoError(t, err)
+		default:
+			_, err := w.Write([]byte(`{"Outputs": ["` + msg.Data.(string) + `"]}`))
+			assert.NoError(t, err)
+		}
+	}))
+	defer srv.Close()
+
+	ao, err := New(WithCUURL(srv.URL))
+	assert.NoError(t, err)
+
+	msgs := make([]Message, 8)
+	for i := range msgs {
+		msgs[i] = Message{Target: testProcess, Data: strconv.Itoa(i)}
+	}
+	results, errs := ao.DryRuns(context.Background(), msgs, 3)
+	assert.Len(t, results, len(msgs))
+	for i := range msgs {
+		if i == 1 {
+			assert.True(t, IsProcessError(errs[i]))
+			assert.Nil(t, results[i])
+			continue
+		}
+		assert.NoError(t, errs[i])
+		assert.Equal(t, []any{strconv.Itoa(i)}, results[i].Outputs)
+	}
+	assert.LessOrEqual(t, maxInFlight.Load(), int32(3))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results, errs = ao.DryRuns(ctx, msgs[:2], 1)
+	for i := range 2 {
+		assert.Nil(t, results[i])
+		assert.ErrorIs(t, errs[i], context.Canceled)
+	}
+}
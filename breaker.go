@@ -0,0 +1,113 @@
+package aogo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// circuitBreaker tracks consecutive failures per unit URL, counting transport errors, including timeouts, and
+// 5xx responses. After threshold failures in a row the circuit of the URL opens and calls fail fast with
+// ErrCircuitOpen until cooldown has passed. Then a single probe call is let through: its success closes the
+// circuit again, its failure reopens it for another cooldown. A nil *circuitBreaker lets every call through.
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+type circuit struct {
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{threshold: threshold, cooldown: cooldown, now: time.Now, circuits: map[string]*circuit{}}
+}
+
+// do runs call against the unit at url unless its circuit is open, and records the outcome.
+func (b *circuitBreaker) do(url string, call func() (*http.Response, error)) (*http.Response, error) {
+	if b == nil {
+		return call()
+	}
+	if err := b.allow(url); err != nil {
+		return nil, err
+	}
+	resp, err := call()
+	b.record(url, resp, err)
+	return resp, err
+}
+
+// allow reports whether a call to url may proceed, granting the probe of a circuit whose cooldown has passed.
+func (b *circuitBreaker) allow(url string) error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c := b.circuits[url]
+	if c == nil || c.failures < b.threshold {
+		return nil
+	}
+	if wait := c.openUntil.Sub(b.now()); wait > 0 {
+		return fmt.Errorf("%w: %s, retry in %s", ErrCircuitOpen, url, wait.Round(time.Millisecond))
+	}
+	if c.probing {
+		return fmt.Errorf("%w: %s, probe in flight", ErrCircuitOpen, url)
+	}
+	c.probing = true
+	return nil
+}
+
+// record counts a failed call to url or closes its circuit after a successful one. A call cancelled by the
+// caller, or one that failed before its request was sent, says nothing about the unit and only gives up the
+// probe it may have held.
+func (b *circuitBreaker) record(url string, resp *http.Response, err error) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c := b.circuits[url]
+	var unsent *unsentError
+	switch {
+	case errors.Is(err, context.Canceled), errors.As(err, &unsent):
+		if c != nil {
+			c.probing = false
+		}
+		return
+	case errors.Is(err, errScheduled), err == nil && resp.StatusCode < http.StatusInternalServerError:
+		delete(b.circuits, url)
+		return
+	}
+	if c == nil {
+		c = &circuit{}
+		b.circuits[url] = c
+	}
+	c.failures++
+	c.probing = false
+	if c.failures >= b.threshold {
+		c.openUntil = b.now().Add(b.cooldown)
+	}
+}
+
+// unsentError marks an error raised while building a request, such as waiting for the rate limiter past the
+// deadline, so the circuit breaker does not count it against the unit.
+type unsentError struct {
+	err error
+}
+
+func (e *unsentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *unsentError) Unwrap() error {
+	return e.err
+}
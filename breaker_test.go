@@ -0,0 +1,132 @@
+package aogo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := newCircuitBreaker(2, time.Minute)
+	b.now = func() time.Time { return now }
+
+	fail := func() (*http.Response, error) { return nil, errors.New("connection refused") }
+	serverError := func() (*http.Response, error) { return &http.Response{StatusCode: http.StatusBadGateway}, nil }
+	ok := func() (*http.Response, error) { return &http.Response{StatusCode: http.StatusNotFound}, nil }
+
+	_, err := b.do("http://cu", fail)
+	assert.Error(t, err)
+	assert.NoError(t, b.allow("http://cu"))
+	_, err = b.do("http://cu", serverError)
+	assert.NoError(t, err)
+
+	// Two failures in a row open the circuit of that URL only.
+	_, err = b.do("http://cu", ok)
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.ErrorContains(t, err, "retry in 1m0s")
+	assert.NoError(t, b.allow("http://other"))
+
+	// After the cooldown a single probe is let through.
+	now = now.Add(time.Minute)
+	assert.NoError(t, b.allow("http://cu"))
+	assert.ErrorContains(t, b.allow("http://cu"), "probe in flight")
+
+	// A cancelled probe gives its turn to the next call, a failed one reopens the circuit.
+	b.record("http://cu", nil, context.Canceled)
+	_, err = b.do("http://cu", fail)
+	assert.Error(t, err)
+	assert.ErrorIs(t, b.allow("http://cu"), ErrCircuitOpen)
+
+	// A successful probe closes it.
+	now = now.Add(time.Minute)
+	_, err = b.do("http://cu", ok)
+	assert.NoError(t, err)
+	_, err = b.do("http://cu", fail)
+	assert.NotErrorIs(t, err, ErrCircuitOpen)
+	assert.NoError(t, b.allow("http://cu"))
+
+	var nilBreaker *circuitBreaker
+	_, err = nilBreaker.do("http://cu", ok)
+	assert.NoError(t, err)
+}
+
+func TestWithCircuitBreaker(t *testing.T) {
+	t.Run("FailFast", func(t *testing.T) {
+		var hits int
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hits++
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer srv.Close()
+
+		ao, err := New(WithCUURL(srv.URL), WithCURetry(RetryPolicy{MaxAttempts: 1}), WithCircuitBreaker(2, time.Minute))
+		assert.NoError(t, err)
+		for range 2 {
+			_, err = ao.LoadResult(testProcess, testMessage)
+			assert.ErrorIs(t, err, ErrServerError)
+		}
+		_, err = ao.LoadResult(testProcess, testMessage)
+		assert.ErrorIs(t, err, ErrCircuitOpen)
+		assert.Equal(t, 2, hits)
+	})
+
+	t.Run("Failover", func(t *testing.T) {
+		var downHits, upHits int
+		down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			downHits++
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer down.Close()
+		up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			upHits++
+			_, err := w.Write([]byte(`{"Messages": [{"Data": "1"}], "Spawns": [], "Outputs": [], "Error": "", "GasUsed": 0}`))
+			assert.NoError(t, err)
+		}))
+		defer up.Close()
+
+		ao, err := New(WithCUURLs([]string{down.URL, up.URL}), WithCURetry(RetryPolicy{MaxAttempts: 1}), WithCircuitBreaker(1, time.Minute))
+		assert.NoError(t, err)
+		_, err = ao.LoadResult(testProcess, testMessage)
+		assert.NoError(t, err)
+
+		// The next request starts with the CU that answered, and the one that failed stays skipped.
+		ao.cu.current.Store(0)
+		_, err = ao.LoadResult(testProcess, testMessage)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, downHits)
+		assert.Equal(t, 2, upHits)
+	})
+
+	t.Run("RateLimited", func(t *testing.T) {
+		var hits int
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hits++
+			_, err := w.Write([]byte(`{"id": "mockMessageID"}`))
+			assert.NoError(t, err)
+		}))
+		defer srv.Close()
+
+		ao, err := New(WithMUURL(srv.URL), WithRateLimit(0.1, 1), WithMUTimeout(50*time.Millisecond), WithCircuitBreaker(1, time.Minute))
+		assert.NoError(t, err)
+		s := setupSigner(t)
+		_, err = ao.SendMessage(testProcess, "data", nil, "", s)
+		assert.NoError(t, err)
+		// Waiting for the limiter would outlast the timeout, so no request is sent and the unit is not blamed.
+		_, err = ao.SendMessage(testProcess, "data", nil, "", s)
+		assert.ErrorContains(t, err, "rate limit")
+		assert.NoError(t, ao.mu.breaker.allow(srv.URL))
+		assert.Equal(t, 1, hits)
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		ao, err := New(WithCircuitBreaker(0, time.Minute))
+		assert.NoError(t, err)
+		assert.Nil(t, ao.cu.breaker)
+	})
+}
@@ -0,0 +1,73 @@
+package aogo
+
+import (
+	"errors"
+
+	"github.com/liteseed/goar/tag"
+)
+
+// MessageBuilder builds a Message with chained calls:
+//
+//	msg := aogo.NewMessageBuilder().Target(process).Action("Balance").Tag("Recipient", address).Build()
+//
+// The zero value is ready to use. A MessageBuilder is not safe for concurrent use; the Message it builds is.
+type MessageBuilder struct {
+	msg  Message
+	tags []tag.Tag
+}
+
+// NewMessageBuilder returns an empty MessageBuilder.
+func NewMessageBuilder() *MessageBuilder {
+	return &MessageBuilder{}
+}
+
+// Target sets the process the message is sent to.
+func (b *MessageBuilder) Target(id string) *MessageBuilder {
+	b.msg.Target = id
+	return b
+}
+
+// Owner sets the address the message is evaluated as. DryRun keeps From in sync with it.
+func (b *MessageBuilder) Owner(address string) *MessageBuilder {
+	b.msg.Owner = address
+	return b
+}
+
+// Action sets the Action tag, replacing an earlier one.
+func (b *MessageBuilder) Action(name string) *MessageBuilder {
+	for i, t := range b.tags {
+		if t.Name == "Action" {
+			b.tags[i].Value = name
+			return b
+		}
+	}
+	return b.Tag("Action", name)
+}
+
+// Tag adds a tag. Tags keep the order they are added in.
+func (b *MessageBuilder) Tag(name string, value string) *MessageBuilder {
+	b.tags = append(b.tags, tag.Tag{Name: name, Value: value})
+	return b
+}
+
+// Data sets the message data.
+func (b *MessageBuilder) Data(s string) *MessageBuilder {
+	b.msg.Data = s
+	return b
+}
+
+// Validate reports a message that cannot be sent, such as one without a valid Target.
+func (b *MessageBuilder) Validate() error {
+	if b.msg.Target == "" {
+		return errors.New("message has no target")
+	}
+	return validateID("target", b.msg.Target)
+}
+
+// Build returns the message. The builder can keep being used without affecting it.
+func (b *MessageBuilder) Build() Message {
+	msg := b.msg
+	tags := append([]tag.Tag{}, b.tags...)
+	msg.Tags = &tags
+	return msg
+}
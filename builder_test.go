@@ -0,0 +1,44 @@
+package aogo
+
+import (
+	"testing"
+
+	"github.com/liteseed/goar/tag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessageBuilder(t *testing.T) {
+	t.Run("Build", func(t *testing.T) {
+		b := NewMessageBuilder().
+			Target(testProcess).
+			Owner(ZeroAddress).
+			Action("Transfer").
+			Tag("Recipient", testMessage).
+			Action("Balance").
+			Data("payload")
+		assert.NoError(t, b.Validate())
+
+		msg := b.Build()
+		assert.Equal(t, testProcess, msg.Target)
+		assert.Equal(t, ZeroAddress, msg.Owner)
+		assert.Equal(t, "payload", msg.Data)
+		assert.Equal(t, []tag.Tag{{Name: "Action", Value: "Balance"}, {Name: "Recipient", Value: testMessage}}, *msg.Tags)
+
+		// Later calls do not change a built message.
+		b.Tag("Extra", "1")
+		assert.Len(t, *msg.Tags, 2)
+		assert.Len(t, *b.Build().Tags, 3)
+	})
+
+	t.Run("ZeroValue", func(t *testing.T) {
+		var b MessageBuilder
+		msg := b.Target(testProcess).Build()
+		assert.Equal(t, testProcess, msg.Target)
+		assert.Empty(t, *msg.Tags)
+	})
+
+	t.Run("Validate", func(t *testing.T) {
+		assert.ErrorContains(t, NewMessageBuilder().Action("Info").Validate(), "no target")
+		assert.ErrorIs(t, NewMessageBuilder().Target("short").Validate(), ErrInvalidID)
+	})
+}
@@ -0,0 +1,107 @@
+package aogo
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// resultCache is a least recently used cache of results whose entries expire after ttl. A nil *resultCache
+// caches nothing.
+type resultCache struct {
+	size int
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+type cacheEntry struct {
+	key     string
+	res     *Response
+	expires time.Time
+}
+
+func newResultCache(size int, ttl time.Duration) *resultCache {
+	if size <= 0 {
+		return nil
+	}
+	return &resultCache{size: size, ttl: ttl, now: time.Now, entries: map[string]*list.Element{}, order: list.New()}
+}
+
+// get returns the result cached under key unless it has expired.
+func (c *resultCache) get(key string) (*Response, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := e.Value.(*cacheEntry)
+	if c.ttl > 0 && !c.now().Before(entry.expires) {
+		c.order.Remove(e)
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.order.MoveToFront(e)
+	return entry.res, true
+}
+
+// put caches res under key, evicting the least recently used entry when the cache is full.
+func (c *resultCache) put(key string, res *Response) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry := &cacheEntry{key: key, res: res, expires: c.now().Add(c.ttl)}
+	if e, ok := c.entries[key]; ok {
+		e.Value = entry
+		c.order.MoveToFront(e)
+		return
+	}
+	c.entries[key] = c.order.PushFront(entry)
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// dryRunCacheSize is how many dry runs WithDryRunCache keeps.
+const dryRunCacheSize = 1024
+
+// resultKey identifies the result of message on process read with params.
+func resultKey(process string, message string, params url.Values) string {
+	return "result\x00" + process + "\x00" + message + "\x00" + params.Encode()
+}
+
+// dryRunKey identifies a dry run of message, or returns false if message cannot be serialized.
+func dryRunKey(message Message) (string, bool) {
+	b, err := json.Marshal(message)
+	if err != nil {
+		return "", false
+	}
+	return "dry-run\x00" + string(b), true
+}
+
+type bypassCacheKey struct{}
+
+// BypassCache returns a context that makes LoadResultContext, DryRunContext and the other calls given it skip
+// the cache set by WithResultCache or WithDryRunCache. The fresh result still replaces the cached one.
+func BypassCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassCacheKey{}, true)
+}
+
+func cacheBypassed(ctx context.Context) bool {
+	bypass, _ := ctx.Value(bypassCacheKey{}).(bool)
+	return bypass
+}
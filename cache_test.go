@@ -0,0 +1,160 @@
+package aogo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/liteseed/goar/tag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResultCache(t *testing.T) {
+	now := time.Unix(0, 0)
+	c := newResultCache(2, time.Minute)
+	c.now = func() time.Time { return now }
+
+	a, b, d := &Response{GasUsed: 1}, &Response{GasUsed: 2}, &Response{GasUsed: 3}
+	c.put("a", a)
+	c.put("b", b)
+	res, ok := c.get("a")
+	assert.True(t, ok)
+	assert.Same(t, a, res)
+
+	// b is now the least recently used entry.
+	c.put("d", d)
+	_, ok = c.get("b")
+	assert.False(t, ok)
+	_, ok = c.get("a")
+	assert.True(t, ok)
+
+	now = now.Add(time.Minute)
+	_, ok = c.get("a")
+	assert.False(t, ok)
+	assert.Equal(t, 1, c.order.Len())
+
+	var nilCache *resultCache
+	nilCache.put("a", a)
+	_, ok = nilCache.get("a")
+	assert.False(t, ok)
+	assert.Nil(t, newResultCache(0, time.Minute))
+}
+
+func TestWithResultCache(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Query().Get("process-id") == testMessage {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, err := w.Write([]byte(`{"Messages": [], "Spawns": [], "Outputs": ["ok"], "GasUsed": 1}`))
+		assert.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	ao, err := New(WithCUURL(srv.URL), WithResultCache(10, time.Minute), WithCURetry(RetryPolicy{MaxAttempts: 1}))
+	assert.NoError(t, err)
+
+	t.Run("LoadResult", func(t *testing.T) {
+		requests.Store(0)
+		first, err := ao.LoadResult(testProcess, testMessage)
+		assert.NoError(t, err)
+		second, err := ao.LoadResult(testProcess, testMessage)
+		assert.NoError(t, err)
+		assert.Same(t, first, second)
+		assert.EqualValues(t, 1, requests.Load())
+
+		_, err = ao.loadResult(context.Background(), testProcess, testMessage, url.Values{"long-poll": {"10"}})
+		assert.NoError(t, err)
+		assert.EqualValues(t, 2, requests.Load())
+
+		fresh, err := ao.LoadResultContext(BypassCache(context.Background()), testProcess, testMessage)
+		assert.NoError(t, err)
+		assert.NotSame(t, first, fresh)
+		assert.EqualValues(t, 3, requests.Load())
+	})
+
+	t.Run("ErrorsNotCached", func(t *testing.T) {
+		requests.Store(0)
+		_, err := ao.LoadResult(testMessage, testProcess)
+		assert.Error(t, err)
+		_, err = ao.LoadResult(testMessage, testProcess)
+		assert.Error(t, err)
+		assert.EqualValues(t, 2, requests.Load())
+	})
+
+	t.Run("NotReady", func(t *testing.T) {
+		empty := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			_, err := w.Write([]byte(`{"Messages": [], "Spawns": [], "Outputs": [], "GasUsed": 0}`))
+			assert.NoError(t, err)
+		}))
+		defer empty.Close()
+		ao, err := New(WithCUURL(empty.URL), WithResultCache(10, time.Minute))
+		assert.NoError(t, err)
+
+		requests.Store(0)
+		_, err = ao.LoadResult(testProcess, testMessage)
+		assert.NoError(t, err)
+		_, err = ao.LoadResult(testProcess, testMessage)
+		assert.NoError(t, err)
+		assert.EqualValues(t, 2, requests.Load())
+	})
+
+	t.Run("DryRun", func(t *testing.T) {
+		requests.Store(0)
+		_, err := ao.DryRunAs(testProcess, testMessage, "Info", nil)
+		assert.NoError(t, err)
+		_, err = ao.DryRunAs(testProcess, testMessage, "Info", nil)
+		assert.NoError(t, err)
+		assert.EqualValues(t, 1, requests.Load())
+
+		_, err = ao.DryRunAs(testProcess, testMessage, "Balance", nil)
+		assert.NoError(t, err)
+		assert.EqualValues(t, 2, requests.Load())
+	})
+}
+
+func TestWithDryRunCache(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		_, err := w.Write([]byte(`{"Messages": [], "Spawns": [], "Outputs": [], "GasUsed": 1}`))
+		assert.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	ao, err := New(WithCUURL(srv.URL), WithDryRunCache(time.Second))
+	assert.NoError(t, err)
+	now := time.Unix(0, 0)
+	ao.dryRuns.now = func() time.Time { return now }
+
+	message := Message{Target: testProcess, Owner: testMessage, Tags: &[]tag.Tag{{Name: "Action", Value: "Info"}}}
+	info := func(ctx context.Context) {
+		_, err := ao.DryRunContext(ctx, message)
+		assert.NoError(t, err)
+	}
+	info(context.Background())
+	info(context.Background())
+	assert.EqualValues(t, 1, requests.Load())
+	assert.Len(t, *message.Tags, 1)
+
+	info(BypassCache(context.Background()))
+	assert.EqualValues(t, 2, requests.Load())
+
+	now = now.Add(time.Second)
+	info(context.Background())
+	assert.EqualValues(t, 3, requests.Load())
+
+	// Results are not cached without WithResultCache.
+	_, err = ao.LoadResult(testProcess, testMessage)
+	assert.NoError(t, err)
+	_, err = ao.LoadResult(testProcess, testMessage)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 5, requests.Load())
+}
@@ -0,0 +1,74 @@
+package aogo
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Close releases the resources of ao for a clean shutdown: it stops the background goroutines of ao and waits
+// for them to return, closes the idle connections of the transports ao created for WithProxy, WithTLSConfig and
+// the other transport options, and flushes the Observer if it is a FlushObserver. Clients passed with
+// WithHTTPClient, and http.DefaultClient, belong to the caller and are left as they are.
+//
+// ao must not be used after Close. Calling Close again does nothing and returns nil.
+func (ao *AO) Close() error {
+	if !ao.background.close() {
+		return nil
+	}
+	for _, t := range ao.transports {
+		t.CloseIdleConnections()
+	}
+	var errs []error
+	if f, ok := ao.cu.observer.(FlushObserver); ok {
+		if err := f.Flush(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// background tracks the goroutines a client runs on its own, such as subscriptions, so Close can stop them.
+// The zero value is ready to use.
+type background struct {
+	mu     sync.Mutex
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	closed bool
+}
+
+// goFunc runs f in a new goroutine with a context that is canceled by close, or fails with ErrClosed after close.
+func (b *background) goFunc(f func(ctx context.Context)) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return ErrClosed
+	}
+	if b.ctx == nil {
+		b.ctx, b.cancel = context.WithCancel(context.Background())
+	}
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		f(b.ctx)
+	}()
+	return nil
+}
+
+// close cancels the context of the running goroutines and waits for them to return. It reports false if b was
+// already closed.
+func (b *background) close() bool {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return false
+	}
+	b.closed = true
+	if b.cancel != nil {
+		b.cancel()
+	}
+	b.mu.Unlock()
+	b.wg.Wait()
+	return true
+}
@@ -0,0 +1,69 @@
+package aogo
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type flushObserver struct {
+	flushed atomic.Int32
+}
+
+func (o *flushObserver) ObserveRequest(method string, unit string, status int, dur time.Duration) {}
+
+func (o *flushObserver) Flush() error {
+	o.flushed.Add(1)
+	return errors.New("flush failed")
+}
+
+func TestClose(t *testing.T) {
+	var closed atomic.Int32
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"Messages": [], "Spawns": [], "Outputs": [], "GasUsed": 0}`))
+		assert.NoError(t, err)
+	}))
+	srv.Config.ConnState = func(c net.Conn, state http.ConnState) {
+		if state == http.StateClosed {
+			closed.Add(1)
+		}
+	}
+	srv.Start()
+	defer srv.Close()
+
+	observer := &flushObserver{}
+	ao, err := New(WithCUURL(srv.URL), WithTLSConfig(nil), WithObserver(observer))
+	assert.NoError(t, err)
+	_, err = ao.LoadResult(testProcess, testMessage)
+	assert.NoError(t, err)
+
+	stopped := make(chan struct{})
+	assert.NoError(t, ao.background.goFunc(func(ctx context.Context) {
+		<-ctx.Done()
+		close(stopped)
+	}))
+
+	err = ao.Close()
+	assert.ErrorContains(t, err, "flush failed")
+	assert.EqualValues(t, 1, observer.flushed.Load())
+	select {
+	case <-stopped:
+	default:
+		t.Fatal("Close returned before the background goroutine stopped")
+	}
+	assert.Eventually(t, func() bool { return closed.Load() == 1 }, time.Second, 10*time.Millisecond)
+
+	assert.NoError(t, ao.Close())
+	assert.EqualValues(t, 1, observer.flushed.Load())
+	assert.ErrorIs(t, ao.background.goFunc(func(ctx context.Context) {}), ErrClosed)
+
+	// A client built as a literal can be closed too.
+	assert.NoError(t, (&AO{}).Close())
+}
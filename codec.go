@@ -0,0 +1,33 @@
+package aogo
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// Codec decodes CU results. The CU is asked for ContentType through the Accept header of its requests.
+// JSONCodec is the default; a deployment that negotiates a more compact encoding, such as msgpack or CBOR,
+// can plug in its own with WithCodec.
+type Codec interface {
+	ContentType() string
+	Decode(r io.Reader, v any) error
+}
+
+// JSONCodec decodes results encoded as JSON.
+type JSONCodec struct{}
+
+func (JSONCodec) ContentType() string {
+	return "application/json"
+}
+
+func (JSONCodec) Decode(r io.Reader, v any) error {
+	return json.NewDecoder(r).Decode(v)
+}
+
+// codecOrDefault returns c, or JSONCodec if c is nil.
+func codecOrDefault(c Codec) Codec {
+	if c == nil {
+		return JSONCodec{}
+	}
+	return c
+}
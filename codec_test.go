@@ -0,0 +1,83 @@
+package aogo
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// base64Codec decodes base64 encoded JSON, standing in for a binary encoding.
+type base64Codec struct{}
+
+func (base64Codec) ContentType() string {
+	return "application/x-base64-json"
+}
+
+func (base64Codec) Decode(r io.Reader, v any) error {
+	return json.NewDecoder(base64.NewDecoder(base64.StdEncoding, r)).Decode(v)
+}
+
+func TestJSONCodec(t *testing.T) {
+	var res Response
+	assert.NoError(t, JSONCodec{}.Decode(strings.NewReader(`{"Messages": [{"Data": "1"}], "GasUsed": "7"}`), &res))
+	assert.Equal(t, "1", res.Messages[0].Data)
+	assert.Equal(t, Gas(7), res.GasUsed)
+	assert.Error(t, JSONCodec{}.Decode(strings.NewReader(`not json`), &res))
+	assert.Equal(t, "application/json", JSONCodec{}.ContentType())
+}
+
+func TestWithCodec(t *testing.T) {
+	body := `{"Messages": [{"Data": "1"}], "Spawns": [], "Outputs": [], "Error": "", "GasUsed": 0}`
+
+	t.Run("Default", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "application/json", r.Header.Get("Accept"))
+			_, err := w.Write([]byte(body))
+			assert.NoError(t, err)
+		}))
+		defer srv.Close()
+
+		ao := &AO{cu: newCU(srv.URL)}
+		res, err := ao.LoadResult(testProcess, testMessage)
+		assert.NoError(t, err)
+		assert.Equal(t, "1", res.Messages[0].Data)
+	})
+
+	t.Run("Custom", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "application/x-base64-json", r.Header.Get("Accept"))
+			_, err := w.Write([]byte(base64.StdEncoding.EncodeToString([]byte(body))))
+			assert.NoError(t, err)
+		}))
+		defer srv.Close()
+
+		ao, err := New(WithCUURL(srv.URL), WithCodec(base64Codec{}))
+		assert.NoError(t, err)
+		res, err := ao.LoadResult(testProcess, testMessage)
+		assert.NoError(t, err)
+		assert.Equal(t, "1", res.Messages[0].Data)
+
+		res, err = ao.DryRun(Message{Target: testProcess})
+		assert.NoError(t, err)
+		assert.Equal(t, "1", res.Messages[0].Data)
+	})
+
+	t.Run("DecodeError", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, err := w.Write([]byte(body))
+			assert.NoError(t, err)
+		}))
+		defer srv.Close()
+
+		ao, err := New(WithCUURL(srv.URL), WithCodec(base64Codec{}))
+		assert.NoError(t, err)
+		_, err = ao.LoadResult(testProcess, testMessage)
+		assert.ErrorContains(t, err, "failed to unmarshal response")
+	})
+}
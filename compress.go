@@ -0,0 +1,86 @@
+package aogo
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// compressRequests is the middleware installed by WithCompression. It gzips the body of every request that has
+// one and sets Content-Encoding accordingly. The upload progress of a body set up by WithUploadProgress is
+// reported on the compressed body, as it is written.
+func compressRequests(next http.RoundTripper) http.RoundTripper {
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Body == nil || req.Body == http.NoBody || req.Header.Get("Content-Encoding") != "" {
+			return next.RoundTrip(req)
+		}
+		// The body is read past its progressReader, which would otherwise report it sent before any byte is.
+		src := io.ReadCloser(req.Body)
+		progress, _ := req.Body.(*progressReader)
+		if progress != nil {
+			src = progress.ReadCloser
+		}
+		body, err := io.ReadAll(src)
+		src.Close()
+		if err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(body); err != nil {
+			return nil, err
+		}
+		if err := zw.Close(); err != nil {
+			return nil, err
+		}
+
+		// A RoundTripper must not modify the request it is given.
+		req = req.Clone(req.Context())
+		compressed := buf.Bytes()
+		newBody := func() io.ReadCloser {
+			r := io.NopCloser(bytes.NewReader(compressed))
+			if progress == nil {
+				return r
+			}
+			return &progressReader{ReadCloser: r, total: int64(len(compressed)), progress: progress.progress}
+		}
+		req.Body = newBody()
+		req.GetBody = func() (io.ReadCloser, error) {
+			return newBody(), nil
+		}
+		req.ContentLength = int64(len(compressed))
+		req.Header.Set("Content-Encoding", "gzip")
+		return next.RoundTrip(req)
+	})
+}
+
+// decompressResponse replaces the body of a gzip encoded response with its decompressed content.
+func decompressResponse(resp *http.Response) error {
+	if !strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		return nil
+	}
+	zr, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		resp.Body.Close()
+		return fmt.Errorf("decompress response: %w", err)
+	}
+	resp.Body = &gzipBody{Reader: zr, body: resp.Body}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return nil
+}
+
+// gzipBody reads a gzip stream and closes the underlying body.
+type gzipBody struct {
+	*gzip.Reader
+	body io.ReadCloser
+}
+
+func (b *gzipBody) Close() error {
+	return b.body.Close()
+}
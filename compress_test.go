@@ -0,0 +1,112 @@
+package aogo
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/liteseed/goar/transaction/data_item"
+	"github.com/stretchr/testify/assert"
+)
+
+func gzipBytes(t *testing.T, b []byte) []byte {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, err := zw.Write(b)
+	assert.NoError(t, err)
+	assert.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func TestGzipResponse(t *testing.T) {
+	body := `{"Messages": [{"Data": "` + strings.Repeat("x", 4096) + `"}], "Spawns": [], "Outputs": [], "Error": "", "GasUsed": 0}`
+
+	t.Run("Decompressed", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "gzip", r.Header.Get("Accept-Encoding"))
+			w.Header().Set("Content-Encoding", "gzip")
+			_, err := w.Write(gzipBytes(t, []byte(body)))
+			assert.NoError(t, err)
+		}))
+		defer srv.Close()
+
+		ao := &AO{cu: newCU(srv.URL)}
+		res, err := ao.LoadResult(testProcess, testMessage)
+		assert.NoError(t, err)
+		assert.Len(t, res.Messages[0].Data, 4096)
+	})
+
+	t.Run("Middleware", func(t *testing.T) {
+		// A custom transport does not decompress on its own.
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Encoding", "gzip")
+			_, err := w.Write(gzipBytes(t, []byte(body)))
+			assert.NoError(t, err)
+		}))
+		defer srv.Close()
+
+		passthrough := func(next http.RoundTripper) http.RoundTripper {
+			return RoundTripperFunc(func(r *http.Request) (*http.Response, error) { return next.RoundTrip(r) })
+		}
+		ao, err := New(WithCUURL(srv.URL), WithMiddleware(passthrough))
+		assert.NoError(t, err)
+		res, err := ao.LoadResult(testProcess, testMessage)
+		assert.NoError(t, err)
+		assert.Len(t, res.Messages[0].Data, 4096)
+	})
+
+	t.Run("Corrupt", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Encoding", "gzip")
+			_, err := w.Write([]byte(body))
+			assert.NoError(t, err)
+		}))
+		defer srv.Close()
+
+		ao, err := New(WithCUURL(srv.URL), WithCURetry(RetryPolicy{MaxAttempts: 1}))
+		assert.NoError(t, err)
+		_, err = ao.LoadResult(testProcess, testMessage)
+		assert.ErrorContains(t, err, "decompress response")
+	})
+}
+
+func TestWithCompression(t *testing.T) {
+	payload := strings.Repeat("compressible ", 1000)
+	var encodings []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encodings = append(encodings, r.Header.Get("Content-Encoding"))
+		if r.Method == http.MethodGet {
+			_, err := w.Write([]byte(`{"Messages": [{"Data": "1"}], "Spawns": [], "Outputs": [], "Error": "", "GasUsed": 0}`))
+			assert.NoError(t, err)
+			return
+		}
+		assert.Equal(t, "gzip", r.Header.Get("Content-Encoding"))
+		zr, err := gzip.NewReader(r.Body)
+		assert.NoError(t, err)
+		b, err := io.ReadAll(zr)
+		assert.NoError(t, err)
+		item, err := data_item.Decode(b)
+		assert.NoError(t, err)
+		data, err := base64.RawURLEncoding.DecodeString(item.Data)
+		assert.NoError(t, err)
+		assert.Equal(t, payload, string(data))
+		_, err = w.Write([]byte(`{"id": "` + item.ID + `"}`))
+		assert.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	ao, err := New(WithMUURL(srv.URL), WithCUURL(srv.URL), WithCompression())
+	assert.NoError(t, err)
+	id, err := ao.SendMessage(testProcess, payload, nil, "", setupSigner(t))
+	assert.NoError(t, err)
+	assert.NotEmpty(t, id)
+
+	_, err = ao.LoadResult(testProcess, testMessage)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"gzip", ""}, encodings)
+}
@@ -0,0 +1,117 @@
+package aogo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// LoadResultConsensus loads the result of message from every CU set by WithCUURLs, or the single CU, and returns
+// it only if at least quorum of them return the same result, compared by Response.Hash. Otherwise the
+// error matches ErrNoConsensus and names every CU that disagreed with the largest group or failed. The CUs are
+// queried concurrently and never fail over to one another. Like LoadResult, a result reporting an Error is
+// returned as a ProcessError.
+func (ao *AO) LoadResultConsensus(process string, message string, quorum int) (*Response, error) {
+	return ao.LoadResultConsensusContext(context.Background(), process, message, quorum)
+}
+
+// LoadResultConsensusContext is like LoadResultConsensus but aborts when ctx is done and traces the call under ctx.
+func (ao *AO) LoadResultConsensusContext(ctx context.Context, process string, message string, quorum int) (res *Response, err error) {
+	ctx, span := ao.startSpan(ctx, "LoadResultConsensus", UnitCU, process)
+	defer func() { span.End(err) }()
+	if ao.compute != nil {
+		return nil, errors.New("consensus needs the HTTP compute unit")
+	}
+	res, err = ao.cu.loadResultConsensus(ctx, process, message, quorum)
+	if err != nil {
+		return nil, err
+	}
+	return processResult(res)
+}
+
+// consensusAnswer is what one CU answered to a consensus read.
+type consensusAnswer struct {
+	url    string
+	result *Response
+	hash   string
+	err    error
+}
+
+func (cu *CU) loadResultConsensus(ctx context.Context, process string, message string, quorum int) (*Response, error) {
+	if err := validateID("process", process); err != nil {
+		return nil, err
+	}
+	if err := validateID("message", message); err != nil {
+		return nil, err
+	}
+	urls := cu.urls
+	if len(urls) == 0 {
+		urls = []string{cu.url}
+	}
+	if quorum < 1 || quorum > len(urls) {
+		return nil, fmt.Errorf("quorum must be between 1 and the %d configured compute units, got %d", len(urls), quorum)
+	}
+
+	ctx, cancel := requestContext(ctx, cu.timeout)
+	defer cancel()
+	path := resultPath(process, message, nil)
+	answers := make([]consensusAnswer, len(urls))
+	var wg sync.WaitGroup
+	for i, u := range urls {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			answers[i] = cu.consensusAnswer(ctx, u, path)
+		}()
+	}
+	wg.Wait()
+
+	counts := map[string]int{}
+	best := ""
+	for _, a := range answers {
+		if a.err != nil {
+			continue
+		}
+		counts[a.hash]++
+		if counts[a.hash] > counts[best] {
+			best = a.hash
+		}
+	}
+	var disagreeing []string
+	var winner *Response
+	for _, a := range answers {
+		switch {
+		case a.err != nil:
+			disagreeing = append(disagreeing, fmt.Sprintf("%s: %v", a.url, a.err))
+		case a.hash != best:
+			disagreeing = append(disagreeing, fmt.Sprintf("%s: result %s", a.url, a.hash))
+		case winner == nil:
+			winner = a.result
+		}
+	}
+	if counts[best] < quorum {
+		return nil, fmt.Errorf("%w: %d of %d compute units agree, need %d; disagreeing: %s", ErrNoConsensus,
+			counts[best], len(urls), quorum, strings.Join(disagreeing, "; "))
+	}
+	return winner, nil
+}
+
+// consensusAnswer loads the result at path from the CU at u alone, retrying according to the CU retry policy.
+func (cu *CU) consensusAnswer(ctx context.Context, u string, path string) consensusAnswer {
+	var b []byte
+	resp, err := cu.breaker.do(u, func() (resp *http.Response, err error) {
+		resp, b, err = cu.send(ctx, "GET", u+path, nil, retryGatewayError)
+		return resp, err
+	})
+	if err != nil {
+		return consensusAnswer{url: u, err: err}
+	}
+	res, err := cu.decodeResult(resp, b)
+	if err != nil {
+		return consensusAnswer{url: u, err: err}
+	}
+	return consensusAnswer{url: u, result: res, hash: res.Hash()}
+}
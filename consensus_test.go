@@ -0,0 +1,56 @@
+package aogo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadResultConsensus(t *testing.T) {
+	serve := func(status int, body string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/result/"+testMessage, r.URL.Path)
+			w.WriteHeader(status)
+			_, err := w.Write([]byte(body))
+			assert.NoError(t, err)
+		}))
+	}
+	// The same result, laid out differently.
+	a := serve(http.StatusOK, `{"Messages": [], "Spawns": [], "Outputs": [{"a": 1, "b": 2}], "Error": "", "GasUsed": 7}`)
+	defer a.Close()
+	b := serve(http.StatusOK, `{"GasUsed": "7", "Outputs": [{"b": 2, "a": 1}], "Spawns": [], "Messages": []}`)
+	defer b.Close()
+	other := serve(http.StatusOK, `{"Messages": [], "Spawns": [], "Outputs": [], "Error": "", "GasUsed": 8}`)
+	defer other.Close()
+	failing := serve(http.StatusBadGateway, `bad gateway`)
+	defer failing.Close()
+
+	ao, err := New(WithCUURLs([]string{a.URL, other.URL, b.URL, failing.URL}), WithCURetry(RetryPolicy{MaxAttempts: 1}))
+	assert.NoError(t, err)
+
+	res, err := ao.LoadResultConsensus(testProcess, testMessage, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, Gas(7), res.GasUsed)
+
+	_, err = ao.LoadResultConsensus(testProcess, testMessage, 3)
+	assert.ErrorIs(t, err, ErrNoConsensus)
+	assert.ErrorContains(t, err, "2 of 4 compute units agree, need 3")
+	assert.ErrorContains(t, err, other.URL)
+	assert.ErrorContains(t, err, failing.URL)
+	assert.NotContains(t, err.Error(), a.URL+":")
+
+	_, err = ao.LoadResultConsensus(testProcess, testMessage, 5)
+	assert.ErrorContains(t, err, "quorum must be between 1 and the 4")
+	_, err = ao.LoadResultConsensus(testProcess, testMessage, 0)
+	assert.ErrorContains(t, err, "quorum")
+
+	t.Run("Single", func(t *testing.T) {
+		ao, err := New(WithCUURL(other.URL))
+		assert.NoError(t, err)
+		res, err := ao.LoadResultConsensus(testProcess, testMessage, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, Gas(8), res.GasUsed)
+	})
+}
@@ -2,69 +2,258 @@ package aogo
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
+	"log/slog"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/liteseed/goar/tag"
 )
 
-type ICU interface {
+// ComputeUnit evaluates messages. CU is the HTTP implementation; tests can supply their own through NewWithUnits.
+type ComputeUnit interface {
 	LoadResult(process string, message string) (*Response, error)
 	DryRun(message Message) (*Response, error)
 }
 
+// ICU is the former name of ComputeUnit.
+//
+// Deprecated: use ComputeUnit.
+type ICU = ComputeUnit
+
 type CU struct {
-	client *http.Client
-	url    string
+	client       *http.Client
+	url          string
+	timeout      time.Duration
+	retry        RetryPolicy
+	logger       *slog.Logger
+	observer     Observer
+	breaker      *circuitBreaker
+	maxErrorBody int
+	// maxResponseBytes caps buffered response bodies, see WithMaxResponseBytes.
+	maxResponseBytes int64
+	// header is added to every request.
+	header http.Header
+	// codec decodes results; nil means JSONCodec.
+	codec Codec
+	// variant is the Variant tag of dry runs; empty means DefaultVariant.
+	variant string
+
+	// urls lists failover endpoints; when empty only url is used.
+	urls []string
+	// current is the index in urls of the endpoint that answered last.
+	current *atomic.Int32
 }
 
 func newCU(url string) CU {
 	return CU{
-		client: http.DefaultClient,
-		url:    url,
+		client:  http.DefaultClient,
+		url:     url,
+		retry:   DefaultRetryPolicy,
+		current: new(atomic.Int32),
 	}
 }
 
 type Response struct {
-	Messages []map[string]any `json:"Messages"`
-	Spawns   []any            `json:"Spawns"`
-	Outputs  []any            `json:"Outputs"`
-	Error    string           `json:"Error"`
-	GasUsed  int              `json:"GasUsed"`
+	Messages []ResultMessage `json:"Messages"`
+	Spawns   []any           `json:"Spawns"`
+	Outputs  []any           `json:"Outputs"`
+	Error    string          `json:"Error"`
+	GasUsed  Gas             `json:"GasUsed"`
+}
+
+// Gas is the amount of gas used by an evaluation. The CU encodes it either as a JSON number or as a string.
+type Gas int64
+
+func (g *Gas) UnmarshalJSON(b []byte) error {
+	return unmarshalFlexInt(b, "gas", (*int64)(g))
+}
+
+// flexInt64 is an integer that units encode either as a JSON number or as a string, like Gas, such as
+// timestamps and block heights.
+type flexInt64 int64
+
+func (n *flexInt64) UnmarshalJSON(b []byte) error {
+	return unmarshalFlexInt(b, "integer", (*int64)(n))
+}
+
+// unmarshalFlexInt decodes a JSON number or a string holding one into n. null leaves n unchanged and an empty
+// string decodes to 0; kind names the value in errors.
+func unmarshalFlexInt(b []byte, kind string, n *int64) error {
+	s := string(b)
+	if s == "null" {
+		return nil
+	}
+	if strings.HasPrefix(s, `"`) {
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+		if s == "" {
+			*n = 0
+			return nil
+		}
+	}
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid %s value %s: %v", kind, b, err)
+	}
+	*n = v
+	return nil
 }
 
 func (cu *CU) LoadResult(process string, message string) (*Response, error) {
-	resp, err := cu.client.Get(fmt.Sprintf("%s/result/%s?process-id=%s", cu.url, message, process))
+	res, err := cu.loadResult(context.Background(), process, message, nil)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode >= http.StatusBadRequest {
-		return nil, fmt.Errorf("cu request failed with status: %s, code: %d, server: %s", resp.Status, resp.StatusCode, resp.Request.Host)
+	return processResult(res)
+}
+
+// processResult turns a result that reports an Error into a ProcessError.
+func processResult(res *Response) (*Response, error) {
+	if res.Error != "" {
+		return nil, &ProcessError{Message: res.Error, GasUsed: res.GasUsed}
+	}
+	return res, nil
+}
+
+// loadResult fetches the result of message without interpreting its Error field. params, if any, are added to
+// the query of the request.
+func (cu *CU) loadResult(ctx context.Context, process string, message string, params url.Values) (*Response, error) {
+	if err := validateID("process", process); err != nil {
+		return nil, err
 	}
-	res, err := io.ReadAll(resp.Body)
+	if err := validateID("message", message); err != nil {
+		return nil, err
+	}
+	ctx, cancel := requestContext(ctx, cu.timeout)
+	defer cancel()
+	resp, res, err := cu.do(ctx, "GET", resultPath(process, message, params), nil, retryGatewayError)
 	if err != nil {
 		return nil, err
 	}
+	return cu.decodeResult(resp, res)
+}
+
+// resultPath returns the CU path of the result of message on process, with params added to its query.
+func resultPath(process string, message string, params url.Values) string {
+	query := url.Values{}
+	for name, values := range params {
+		query[name] = append([]string(nil), values...)
+	}
+	query.Set("process-id", process)
+	return fmt.Sprintf("/result/%s?%s", message, query.Encode())
+}
+
+// decodeResult decodes res, the body of resp, as a result.
+func (cu *CU) decodeResult(resp *http.Response, res []byte) (*Response, error) {
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, fmt.Errorf("load result: %w", newAOError(UnitCU, resp, res, cu.maxErrorBody))
+	}
+	if len(bytes.TrimSpace(res)) == 0 {
+		return nil, fmt.Errorf("load result: %w", ErrEmptyResult)
+	}
 	var readResult Response
-	err = json.Unmarshal(res, &readResult)
+	err := codecOrDefault(cu.codec).Decode(bytes.NewReader(res), &readResult)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %v", err)
+		return nil, unmarshalError("response", err, res, cu.maxErrorBody)
 	}
 	return &readResult, nil
 }
 
+// ResultsQuery selects a page of the results of a process, see ProcessResults. Fields left at their zero value
+// take the defaults of the CU, which returns the first 25 results in ascending order.
+type ResultsQuery struct {
+	// From and To are cursors of earlier results bounding the page. The CU evaluates the process only up to To,
+	// so a page read with it stays the same however many messages the process receives afterwards.
+	From string
+	To   string
+	// Sort is "ASC" or "DESC".
+	Sort string
+	// Limit is the number of results in the page, which the CU caps.
+	Limit int
+}
+
+// ResultEdge is a result in a ResultsPage with the cursor that marks its place among the results of the process.
+type ResultEdge struct {
+	Cursor string   `json:"cursor"`
+	Node   Response `json:"node"`
+}
+
+// ResultsPage is one page of the results of a process.
+type ResultsPage struct {
+	Edges []ResultEdge `json:"edges"`
+}
+
+// results reads the page of the results of process selected by q.
+func (cu *CU) results(ctx context.Context, process string, q ResultsQuery) (*ResultsPage, error) {
+	if err := validateID("process", process); err != nil {
+		return nil, err
+	}
+	query := url.Values{}
+	if q.From != "" {
+		query.Set("from", q.From)
+	}
+	if q.To != "" {
+		query.Set("to", q.To)
+	}
+	if q.Sort != "" {
+		query.Set("sort", q.Sort)
+	}
+	if q.Limit > 0 {
+		query.Set("limit", strconv.Itoa(q.Limit))
+	}
+	path := "/results/" + process
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	ctx, cancel := requestContext(ctx, cu.timeout)
+	defer cancel()
+	resp, body, err := cu.do(ctx, "GET", path, nil, retryGatewayError)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, fmt.Errorf("load results: %w", newAOError(UnitCU, resp, body, cu.maxErrorBody))
+	}
+	var page ResultsPage
+	if err := codecOrDefault(cu.codec).Decode(bytes.NewReader(body), &page); err != nil {
+		return nil, unmarshalError("results", err, body, cu.maxErrorBody)
+	}
+	return &page, nil
+}
+
+// DryRun evaluates message without persisting it. Owner and From are kept in sync so the CU evaluates
+// the message as that address; when neither is set the message acts as ZeroAddress.
 func (cu *CU) DryRun(message Message) (*Response, error) {
-	if message.Tags == nil {
-		message.Tags = &[]tag.Tag{}
-	}
-	*message.Tags = append(*message.Tags,
-		tag.Tag{Name: "Data-Protocol", Value: "ao"},
-		tag.Tag{Name: "Type", Value: "Message"},
-		tag.Tag{Name: "Variant", Value: "ao.TN.1"},
-	)
+	return cu.dryRun(context.Background(), message)
+}
+
+func (cu *CU) dryRun(ctx context.Context, message Message) (*Response, error) {
+	if message.Owner == "" {
+		message.Owner = message.From
+	}
+	if message.Owner == "" {
+		message.Owner = ZeroAddress
+	}
+	if message.From == "" {
+		message.From = message.Owner
+	}
+	// The tags are copied so the caller's message, which may be reused as a cache key, is left as it was.
+	var tags []tag.Tag
+	if message.Tags != nil {
+		tags = append(tags, *message.Tags...)
+	}
+	tags = append(tags, missingTags(tags, protocolTags("Message", cu.variant)...)...)
+	message.Tags = &tags
 	if message.Data == "" {
 		message.Data = "1984"
 	}
@@ -72,27 +261,126 @@ func (cu *CU) DryRun(message Message) (*Response, error) {
 	if err != nil {
 		return nil, err
 	}
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/dry-run?process-id=%s", cu.url, message.Target), bytes.NewBuffer(body))
+	ctx, cancel := requestContext(ctx, cu.timeout)
+	defer cancel()
+	resp, res, err := cu.do(ctx, "POST", fmt.Sprintf("/dry-run?process-id=%s", message.Target), body, nil)
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("content-type", "application/json")
-	resp, err := cu.client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
 	if resp.StatusCode >= http.StatusBadRequest {
-		return nil, fmt.Errorf("dry-run request failed with status: %s, code: %d, server: %s", resp.Status, resp.StatusCode, req.URL.Host)
+		return nil, fmt.Errorf("dry-run: %w", newAOError(UnitCU, resp, res, cu.maxErrorBody))
 	}
-	res, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
+	if len(bytes.TrimSpace(res)) == 0 {
+		return nil, fmt.Errorf("dry-run: %w", ErrEmptyResult)
 	}
 	var dryRun Response
-	err = json.Unmarshal(res, &dryRun)
+	err = codecOrDefault(cu.codec).Decode(bytes.NewReader(res), &dryRun)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal dry-run response: %v", err)
+		return nil, unmarshalError("dry-run response", err, res, cu.maxErrorBody)
+	}
+	if dryRun.Error != "" {
+		return nil, &ProcessError{Message: dryRun.Error, GasUsed: dryRun.GasUsed}
 	}
 	return &dryRun, nil
 }
+
+// do sends a request for path to the CU and returns the final response with its body. A nil retryable sends
+// the request once per URL.
+// When several URLs are configured, transport errors and 5xx responses fail over to the next URL in
+// round-robin order, and the error returned once every URL has failed lists each failure. URLs whose circuit
+// is open count as failed without being sent a request.
+func (cu *CU) do(ctx context.Context, method string, path string, body []byte, retryable func(*http.Response, error) bool) (*http.Response, []byte, error) {
+	urls := cu.urls
+	if len(urls) == 0 {
+		urls = []string{cu.url}
+	}
+	start := 0
+	if cu.current != nil {
+		start = int(cu.current.Load())
+	}
+
+	var errs []error
+	for i := range urls {
+		n := (start + i) % len(urls)
+		var b []byte
+		resp, err := cu.breaker.do(urls[n], func() (resp *http.Response, err error) {
+			resp, b, err = cu.send(ctx, method, urls[n]+path, body, retryable)
+			return resp, err
+		})
+		if err == nil && resp.StatusCode < http.StatusInternalServerError {
+			if cu.current != nil {
+				cu.current.Store(int32(n))
+			}
+			return resp, b, nil
+		}
+		if len(urls) == 1 {
+			return resp, b, err
+		}
+		if err == nil {
+			err = newAOError(UnitCU, resp, b, cu.maxErrorBody)
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", urls[n], err))
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, nil, fmt.Errorf("all compute units failed: %w", errors.Join(errs...))
+}
+
+// send issues a single request to u, retrying according to the CU retry policy.
+func (cu *CU) send(ctx context.Context, method string, u string, body []byte, retryable func(*http.Response, error) bool) (*http.Response, []byte, error) {
+	resp, err := cu.retry.do(ctx, cu.roundTrip, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		if body != nil {
+			req.Header.Set("content-type", "application/json")
+		}
+		req.Header.Set("accept", codecOrDefault(cu.codec).ContentType())
+		return req, nil
+	}, retryable)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	b, err := readBody(resp.Body, cu.maxResponseBytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp, b, nil
+}
+
+// stream sends a GET request for path to the CU endpoint that answered last, retrying according to the CU retry
+// policy, and returns the response without reading its body. cancel releases the request context once the body
+// is no longer needed.
+func (cu *CU) stream(ctx context.Context, path string) (resp *http.Response, cancel context.CancelFunc, err error) {
+	u := cu.url
+	if len(cu.urls) > 0 {
+		n := 0
+		if cu.current != nil {
+			n = int(cu.current.Load()) % len(cu.urls)
+		}
+		u = cu.urls[n]
+	}
+	ctx, cancel = requestContext(ctx, cu.timeout)
+	resp, err = cu.breaker.do(u, func() (*http.Response, error) {
+		return cu.retry.do(ctx, cu.roundTrip, func() (*http.Request, error) {
+			req, err := http.NewRequestWithContext(ctx, "GET", u+path, nil)
+			if err != nil {
+				return nil, err
+			}
+			req.Header.Set("accept", codecOrDefault(cu.codec).ContentType())
+			return req, nil
+		}, retryGatewayError)
+	})
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	return resp, cancel, nil
+}
+
+func (cu *CU) roundTrip(req *http.Request) (*http.Response, error) {
+	return sendRequest(cu.client, cu.logger, cu.observer, UnitCU, cu.header, req)
+}
@@ -1,8 +1,14 @@
 package aogo
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
 	"testing"
+	"time"
 
 	"net/http"
 	"net/http/httptest"
@@ -68,11 +74,18 @@ func TestLoadResult(t *testing.T) {
 
 		res, err := ao.LoadResult(process, message)
 		assert.NoError(t, err)
-		assert.Equal(t, messages[0]["Target"], res.Messages[0]["Target"].(string))
-		assert.Equal(t, messages[0]["Anchor"], res.Messages[0]["Anchor"].(string))
-		assert.Equal(t, messages[0]["Data"], res.Messages[0]["Data"].(string))
-		assert.ElementsMatch(t, messages[0]["Tags"], res.Messages[0]["Tags"])
-		assert.Equal(t, res.GasUsed, 599159077)
+		assert.Equal(t, messages[0]["Target"], res.Messages[0].Target)
+		assert.Equal(t, messages[0]["Anchor"], res.Messages[0].Anchor)
+		assert.Equal(t, messages[0]["Data"], res.Messages[0].Data)
+		assert.ElementsMatch(t, []tag.Tag{
+			{Name: "Data-Protocol", Value: "ao"},
+			{Name: "Variant", Value: "ao.TN.1"},
+			{Name: "Type", Value: "Message"},
+			{Name: "From-Process", Value: "W7Ax6G1i3C4ksRRNP4Urxvq9bcSmwBK9J0S3QBt9J70"},
+			{Name: "From-Module", Value: "2rEYpGAF-zuvgKh8-7fie7TLUdXCS1ZHa7GJ_lw3jpo"},
+			{Name: "Ref_", Value: "43"},
+		}, res.Messages[0].Tags)
+		assert.Equal(t, res.GasUsed, Gas(599159077))
 	})
 	t.Run("1", func(t *testing.T) {
 		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -85,10 +98,10 @@ func TestLoadResult(t *testing.T) {
 
 		ao := &AO{cu: newCU(srv.URL)}
 
-		resp, err := ao.LoadResult("process", "message")
+		resp, err := ao.LoadResult(testProcess, testMessage)
 		assert.NoError(t, err)
 		assert.NotNil(t, resp)
-		assert.Equal(t, 0, resp.GasUsed)
+		assert.Equal(t, Gas(0), resp.GasUsed)
 	})
 }
 
@@ -113,5 +126,232 @@ func TestDryRun(t *testing.T) {
 	resp, err := ao.DryRun(m)
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
-	assert.Equal(t, 0, resp.GasUsed)
+	assert.Equal(t, Gas(0), resp.GasUsed)
+}
+
+func TestCUTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer srv.Close()
+
+	ao, err := New(WithCUURL(srv.URL), WithCUTimeout(20*time.Millisecond))
+	assert.NoError(t, err)
+
+	_, err = ao.LoadResult(testProcess, testMessage)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	_, err = ao.DryRun(Message{Target: "process"})
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestGasUnmarshal(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		in   string
+		want Gas
+	}{
+		{"Number", `{"GasUsed": 599159077}`, 599159077},
+		{"String", `{"GasUsed": "599159077"}`, 599159077},
+		{"Int64", `{"GasUsed": 9007199254740993}`, 9007199254740993},
+		{"QuotedInt64", `{"GasUsed": "9223372036854775807"}`, 9223372036854775807},
+		{"Null", `{"GasUsed": null}`, 0},
+		{"Empty", `{"GasUsed": ""}`, 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var res Response
+			assert.NoError(t, json.Unmarshal([]byte(tc.in), &res))
+			assert.Equal(t, tc.want, res.GasUsed)
+		})
+	}
+
+	var res Response
+	assert.Error(t, json.Unmarshal([]byte(`{"GasUsed": "lots"}`), &res))
+}
+
+func TestDryRunIdentity(t *testing.T) {
+	var got Message
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = Message{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		_, err := w.Write([]byte(`{"Messages": [], "Spawns": [], "Outputs": [], "Error": "", "GasUsed": 0}`))
+		assert.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	ao := &AO{cu: newCU(srv.URL)}
+
+	t.Run("DryRunAs", func(t *testing.T) {
+		_, err := ao.DryRunAs("process", "caller", "Balance", []tag.Tag{{Name: "Target", Value: "someone"}})
+		assert.NoError(t, err)
+		assert.Equal(t, "process", got.Target)
+		assert.Equal(t, "caller", got.Owner)
+		assert.Equal(t, "caller", got.From)
+		assert.Contains(t, *got.Tags, tag.Tag{Name: "Action", Value: "Balance"})
+		assert.Contains(t, *got.Tags, tag.Tag{Name: "Target", Value: "someone"})
+	})
+
+	t.Run("OwnerOnly", func(t *testing.T) {
+		_, err := ao.DryRun(Message{Target: "process", Owner: "owner"})
+		assert.NoError(t, err)
+		assert.Equal(t, "owner", got.From)
+	})
+
+	t.Run("EmptyFrom", func(t *testing.T) {
+		_, err := ao.DryRunAs("process", "", "Info", nil)
+		assert.NoError(t, err)
+		assert.Equal(t, ZeroAddress, got.Owner)
+		assert.Equal(t, ZeroAddress, got.From)
+	})
+
+	t.Run("DryRunSigned", func(t *testing.T) {
+		s := setupSigner(t)
+		_, err := ao.DryRunSigned(Message{Target: "process"}, s)
+		assert.NoError(t, err)
+		assert.Equal(t, s.Address, got.Owner)
+		assert.Equal(t, s.Address, got.From)
+
+		_, err = ao.DryRunSigned(Message{Target: "process", Owner: "owner"}, s)
+		assert.NoError(t, err)
+		assert.Equal(t, "owner", got.Owner)
+	})
+
+	t.Run("DryRunSignedDefault", func(t *testing.T) {
+		s := setupSigner(t)
+		ao := &AO{cu: newCU(srv.URL), signer: s}
+		_, err := ao.DryRunSigned(Message{Target: "process"}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, s.Address, got.Owner)
+	})
+
+	t.Run("DryRunSignedNoSigner", func(t *testing.T) {
+		_, err := ao.DryRunSigned(Message{Target: "process"}, nil)
+		assert.ErrorIs(t, err, ErrInvalidSigner)
+	})
+}
+
+func TestCUFailover(t *testing.T) {
+	ok := `{"Messages": [], "Spawns": [], "Outputs": [], "Error": "", "GasUsed": 0}`
+
+	t.Run("NextEndpoint", func(t *testing.T) {
+		var downHits, upHits int
+		down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			downHits++
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer down.Close()
+		up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			upHits++
+			_, err := w.Write([]byte(ok))
+			assert.NoError(t, err)
+		}))
+		defer up.Close()
+
+		ao, err := New(WithCUURLs([]string{down.URL, up.URL}), WithCURetry(NoRetry))
+		assert.NoError(t, err)
+
+		_, err = ao.LoadResult(testProcess, testMessage)
+		assert.NoError(t, err)
+		_, err = ao.DryRun(Message{Target: "process"})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, downHits)
+		assert.Equal(t, 2, upHits)
+	})
+
+	t.Run("ClientErrorDoesNotFailOver", func(t *testing.T) {
+		var secondHits int
+		first := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer first.Close()
+		second := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			secondHits++
+		}))
+		defer second.Close()
+
+		ao, err := New(WithCUURLs([]string{first.URL, second.URL}))
+		assert.NoError(t, err)
+
+		_, err = ao.LoadResult(testProcess, testMessage)
+		var aoErr *AOError
+		assert.True(t, errors.As(err, &aoErr))
+		assert.Equal(t, http.StatusNotFound, aoErr.StatusCode)
+		assert.Equal(t, 0, secondHits)
+	})
+
+	t.Run("AllFail", func(t *testing.T) {
+		first := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer first.Close()
+		second := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer second.Close()
+
+		ao, err := New(WithCUURLs([]string{first.URL, second.URL}), WithCURetry(NoRetry))
+		assert.NoError(t, err)
+
+		_, err = ao.LoadResult(testProcess, testMessage)
+		assert.ErrorContains(t, err, first.URL)
+		assert.ErrorContains(t, err, second.URL)
+		assert.ErrorContains(t, err, "500")
+		assert.ErrorContains(t, err, "502")
+		var aoErr *AOError
+		assert.True(t, errors.As(err, &aoErr))
+	})
+}
+
+func TestProcessResults(t *testing.T) {
+	// The CU holds five results with cursors c1 to c5; from is exclusive and to inclusive.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/results/"+testProcess {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		q := r.URL.Query()
+		var edges []ResultEdge
+		for i := 1; i <= 5; i++ {
+			cursor := fmt.Sprintf("c%d", i)
+			if q.Has("from") && cursor <= q.Get("from") || q.Has("to") && cursor > q.Get("to") {
+				continue
+			}
+			edges = append(edges, ResultEdge{Cursor: cursor, Node: Response{GasUsed: Gas(i)}})
+		}
+		if q.Get("sort") == "DESC" {
+			slices.Reverse(edges)
+		}
+		limit := 25
+		if q.Has("limit") {
+			limit, _ = strconv.Atoi(q.Get("limit"))
+		}
+		edges = edges[:min(limit, len(edges))]
+		assert.NoError(t, json.NewEncoder(w).Encode(ResultsPage{Edges: edges}))
+	}))
+	defer srv.Close()
+	ao := &AO{cu: newCU(srv.URL)}
+
+	gas := func(q ResultsQuery) []Gas {
+		page, err := ao.ProcessResults(testProcess, q)
+		assert.NoError(t, err)
+		var gas []Gas
+		for _, e := range page.Edges {
+			gas = append(gas, e.Node.GasUsed)
+		}
+		return gas
+	}
+	assert.Equal(t, []Gas{1, 2, 3, 4, 5}, gas(ResultsQuery{}))
+	assert.Equal(t, []Gas{5, 4}, gas(ResultsQuery{Sort: "DESC", Limit: 2}))
+	assert.Equal(t, []Gas{3, 4}, gas(ResultsQuery{From: "c2", To: "c4"}))
+	assert.Equal(t, []Gas{3, 2, 1}, gas(ResultsQuery{To: "c3", Sort: "DESC"}))
+
+	_, err := ao.ProcessResults("process", ResultsQuery{})
+	assert.ErrorIs(t, err, ErrInvalidID)
+	_, err = (&AO{cu: newCU(srv.URL + "/missing")}).ProcessResults(testProcess, ResultsQuery{})
+	assert.ErrorContains(t, err, "404")
+	_, err = (&AO{compute: &stubCU{}}).ProcessResults(testProcess, ResultsQuery{})
+	assert.ErrorContains(t, err, "HTTP compute unit")
 }
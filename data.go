@@ -0,0 +1,73 @@
+package aogo
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// GetData returns the data of the transaction or data item txID as served by the gateway. Bodies larger than
+// the limit set with WithMaxResponseBytes fail with ErrResponseTooLarge; read those with GetDataStream.
+func (ao *AO) GetData(txID string) ([]byte, error) {
+	return ao.GetDataContext(context.Background(), txID)
+}
+
+// GetDataContext is like GetData but aborts when ctx is done.
+func (ao *AO) GetDataContext(ctx context.Context, txID string) ([]byte, error) {
+	r, err := ao.GetDataStreamContext(ctx, txID)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return readBody(r, ao.gateway.maxResponseBytes)
+}
+
+// GetDataStream is like GetData but returns the data without buffering it. The caller must Close the returned
+// reader. The gateway timeout, if any, also bounds reading the body.
+func (ao *AO) GetDataStream(txID string) (io.ReadCloser, error) {
+	return ao.GetDataStreamContext(context.Background(), txID)
+}
+
+// GetDataStreamContext is like GetDataStream but aborts when ctx is done, including while the body is read.
+func (ao *AO) GetDataStreamContext(ctx context.Context, txID string) (io.ReadCloser, error) {
+	if err := validateID("transaction", txID); err != nil {
+		return nil, err
+	}
+	resp, cancel, err := ao.gateway.get(ctx, "/"+txID, "*/*")
+	if err != nil {
+		return nil, fmt.Errorf("data: %w", err)
+	}
+	return &streamBody{ReadCloser: resp.Body, cancel: cancel}, nil
+}
+
+// get sends a GET request for path to the gateway, retrying according to the gateway retry policy, and returns
+// the response without reading its body. Responses with an error status are read and returned as an AOError.
+// cancel releases the request context once the body is no longer needed.
+func (g *Gateway) get(ctx context.Context, path string, accept string) (resp *http.Response, cancel context.CancelFunc, err error) {
+	ctx, cancel = requestContext(ctx, g.timeout)
+	resp, err = g.breaker.do(g.url, func() (*http.Response, error) {
+		return g.retry.do(ctx, g.roundTrip, func() (*http.Request, error) {
+			req, err := http.NewRequestWithContext(ctx, "GET", g.url+path, nil)
+			if err != nil {
+				return nil, err
+			}
+			req.Header.Set("accept", accept)
+			return req, nil
+		}, retryGatewayError)
+	})
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		defer cancel()
+		defer resp.Body.Close()
+		b, err := readBody(resp.Body, g.maxResponseBytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		return nil, nil, newAOError(UnitGateway, resp, b, g.maxErrorBody)
+	}
+	return resp, cancel, nil
+}
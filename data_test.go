@@ -0,0 +1,58 @@
+package aogo
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetData(t *testing.T) {
+	payload := strings.Repeat("ao", 64)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/"+testMessage {
+			http.Error(w, "Not Found", http.StatusNotFound)
+			return
+		}
+		_, err := w.Write([]byte(payload))
+		assert.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	ao, err := New(WithGatewayURL(srv.URL))
+	assert.NoError(t, err)
+
+	b, err := ao.GetData(testMessage)
+	assert.NoError(t, err)
+	assert.Equal(t, payload, string(b))
+
+	r, err := ao.GetDataStream(testMessage)
+	assert.NoError(t, err)
+	b, err = io.ReadAll(r)
+	assert.NoError(t, err)
+	assert.NoError(t, r.Close())
+	assert.Equal(t, payload, string(b))
+
+	_, err = ao.GetData(testProcess)
+	var aoErr *AOError
+	assert.ErrorAs(t, err, &aoErr)
+	assert.Equal(t, UnitGateway, aoErr.Unit)
+	assert.Equal(t, http.StatusNotFound, aoErr.StatusCode)
+
+	_, err = ao.GetData("bad")
+	assert.ErrorIs(t, err, ErrInvalidID)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = ao.GetDataContext(ctx, testMessage)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	small, err := New(WithGatewayURL(srv.URL), WithMaxResponseBytes(16))
+	assert.NoError(t, err)
+	_, err = small.GetData(testMessage)
+	assert.ErrorIs(t, err, ErrResponseTooLarge)
+}
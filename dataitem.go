@@ -0,0 +1,259 @@
+package aogo
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/liteseed/goar/crypto"
+	"github.com/liteseed/goar/tag"
+	"github.com/liteseed/goar/transaction/data_item"
+)
+
+// DataItemID returns the ID of the signed ANS-104 data item item: the base64url SHA-256 of its signature.
+// It is the ID the MU should answer with once the item is submitted, so it can be used to correlate logs
+// before the MU responds or to check the ID it returns.
+func DataItemID(item []byte) (string, error) {
+	if len(item) < 2 {
+		return "", errors.New("data item too short")
+	}
+	signatureType := int(binary.LittleEndian.Uint16(item))
+	config, ok := data_item.SignatureConfig[signatureType]
+	if !ok {
+		return "", fmt.Errorf("unsupported signature type %d", signatureType)
+	}
+	end := 2 + config.SignatureLength
+	if len(item) < end {
+		return "", errors.New("data item too short")
+	}
+	sum := sha256.Sum256(item[2:end])
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+// DataItem is a signed ANS-104 data item decoded by DecodeDataItem.
+type DataItem struct {
+	// ID is the base64url SHA-256 of Signature.
+	ID            string
+	SignatureType int
+	Signature     []byte
+	Owner         []byte
+	// Target is the base64url ID of the recipient, empty if the item has none.
+	Target string
+	// Anchor is empty if the item has none.
+	Anchor string
+	Tags   []tag.Tag
+	Data   []byte
+	// Raw is the encoded item DataItem was decoded from.
+	Raw []byte
+
+	// tags is the encoded tag section, which VerifyDataItem hashes as is.
+	tags []byte
+}
+
+// DecodeDataItem parses the signed ANS-104 data item item. Malformed items, such as truncated ones or ones whose
+// tag section does not match its declared size, fail with ErrInvalidDataItem. The signature is not checked.
+func DecodeDataItem(item []byte) (*DataItem, error) {
+	d := dataItemDecoder{b: item}
+	header := d.next(2)
+	if d.err != nil {
+		return nil, d.err
+	}
+	signatureType := int(binary.LittleEndian.Uint16(header))
+	config, ok := data_item.SignatureConfig[signatureType]
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported signature type %d", ErrInvalidDataItem, signatureType)
+	}
+	signature := d.next(config.SignatureLength)
+	owner := d.next(config.PublicKeyLength)
+	target := d.optional("target", 32)
+	anchor := d.optional("anchor", 32)
+	count := d.uint64()
+	size := d.uint64()
+	if d.err != nil {
+		return nil, d.err
+	}
+	if count > data_item.MAX_TAGS {
+		return nil, fmt.Errorf("%w: %d tags, at most %d allowed", ErrInvalidDataItem, count, data_item.MAX_TAGS)
+	}
+	if size > uint64(len(item)-d.pos) {
+		return nil, fmt.Errorf("%w: tags are %d bytes but only %d are left", ErrInvalidDataItem, size, len(item)-d.pos)
+	}
+	rawTags := d.next(int(size))
+	tags, err := decodeAvroTags(rawTags)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDataItem, err)
+	}
+	if uint64(len(tags)) != count {
+		return nil, fmt.Errorf("%w: %d tags declared but %d encoded", ErrInvalidDataItem, count, len(tags))
+	}
+
+	sum := sha256.Sum256(signature)
+	return &DataItem{
+		ID:            base64.RawURLEncoding.EncodeToString(sum[:]),
+		SignatureType: signatureType,
+		Signature:     signature,
+		Owner:         owner,
+		Target:        base64.RawURLEncoding.EncodeToString(target),
+		Anchor:        string(anchor),
+		Tags:          tags,
+		Data:          item[d.pos:],
+		Raw:           item,
+		tags:          rawTags,
+	}, nil
+}
+
+// OwnerAddress returns the address of the owner of d: the base64url SHA-256 of its public key, as for Arweave
+// wallets.
+func (d *DataItem) OwnerAddress() string {
+	sum := sha256.Sum256(d.Owner)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// signatureVerifiers check a signature over a data item hash by signature type.
+var signatureVerifiers = map[int]func(owner, hash, signature []byte) bool{
+	data_item.Arweave: func(owner, hash, signature []byte) bool {
+		key := &rsa.PublicKey{N: new(big.Int).SetBytes(owner), E: 65537}
+		return crypto.Verify(hash, signature, key) == nil
+	},
+	data_item.ED25519: func(owner, hash, signature []byte) bool {
+		return ed25519.Verify(owner, hash, signature)
+	},
+}
+
+// VerifyDataItem reports whether the signature of item was made by the key in its Owner over its other fields
+// and its ID matches the signature, so OwnerAddress can be trusted. The error is set when the signature cannot
+// be checked at all, for example because the signature type is not supported; Arweave (RSA) and ED25519
+// signatures are.
+func VerifyDataItem(item *DataItem) (bool, error) {
+	config, ok := data_item.SignatureConfig[item.SignatureType]
+	if !ok {
+		return false, fmt.Errorf("%w: unsupported signature type %d", ErrInvalidDataItem, item.SignatureType)
+	}
+	verify, ok := signatureVerifiers[item.SignatureType]
+	if !ok {
+		return false, fmt.Errorf("verifying %s signatures is not supported", config.Name)
+	}
+	if len(item.Owner) != config.PublicKeyLength || len(item.Signature) != config.SignatureLength {
+		return false, fmt.Errorf("%w: %s owner or signature has the wrong length", ErrInvalidDataItem, config.Name)
+	}
+	sum := sha256.Sum256(item.Signature)
+	if base64.RawURLEncoding.EncodeToString(sum[:]) != item.ID {
+		return false, nil
+	}
+
+	target, err := base64.RawURLEncoding.DecodeString(item.Target)
+	if err != nil {
+		return false, fmt.Errorf("%w: target: %v", ErrInvalidDataItem, err)
+	}
+	tags := item.tags
+	if tags == nil && len(item.Tags) > 0 {
+		// Items not built by DecodeDataItem have their tags encoded again.
+		if tags, err = tag.Serialize(&item.Tags); err != nil {
+			return false, err
+		}
+	}
+	hash := dataItemHash(item.SignatureType, item.Owner, target, []byte(item.Anchor), tags, item.Data)
+	return verify(item.Owner, hash[:], item.Signature), nil
+}
+
+// dataItemDecoder reads the fields of a data item in order, recording the first field that runs past its end.
+type dataItemDecoder struct {
+	b   []byte
+	pos int
+	err error
+}
+
+// next returns the following n bytes, or nil once an error has been recorded.
+func (d *dataItemDecoder) next(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n > len(d.b)-d.pos {
+		d.err = fmt.Errorf("%w: truncated at byte %d", ErrInvalidDataItem, d.pos)
+		return nil
+	}
+	b := d.b[d.pos : d.pos+n]
+	d.pos += n
+	return b
+}
+
+// optional reads an optional field of n bytes preceded by its presence byte.
+func (d *dataItemDecoder) optional(name string, n int) []byte {
+	presence := d.next(1)
+	if d.err != nil {
+		return nil
+	}
+	switch presence[0] {
+	case 0:
+		return nil
+	case 1:
+		return d.next(n)
+	}
+	d.err = fmt.Errorf("%w: %s presence byte is %d", ErrInvalidDataItem, name, presence[0])
+	return nil
+}
+
+// uint64 reads a little endian 64 bit integer.
+func (d *dataItemDecoder) uint64() uint64 {
+	b := d.next(8)
+	if d.err != nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint64(b)
+}
+
+// decodeAvroTags decodes the Avro array of name and value byte strings ANS-104 encodes tags as.
+func decodeAvroTags(b []byte) ([]tag.Tag, error) {
+	var tags []tag.Tag
+	for len(b) > 0 {
+		count, n := binary.Varint(b)
+		if n <= 0 {
+			return nil, errors.New("invalid tag block count")
+		}
+		b = b[n:]
+		if count == 0 {
+			break
+		}
+		if count < 0 {
+			// A negative count is followed by the size of the block in bytes.
+			count = -count
+			_, n := binary.Varint(b)
+			if n <= 0 {
+				return nil, errors.New("invalid tag block size")
+			}
+			b = b[n:]
+		}
+		if count > data_item.MAX_TAGS-int64(len(tags)) {
+			return nil, fmt.Errorf("more than %d tags", data_item.MAX_TAGS)
+		}
+		for i := int64(0); i < count; i++ {
+			var name, value []byte
+			var err error
+			if name, b, err = avroBytes(b); err != nil {
+				return nil, fmt.Errorf("tag %d name: %v", len(tags), err)
+			}
+			if value, b, err = avroBytes(b); err != nil {
+				return nil, fmt.Errorf("tag %d value: %v", len(tags), err)
+			}
+			tags = append(tags, tag.Tag{Name: string(name), Value: string(value)})
+		}
+		if len(b) == 0 {
+			return nil, errors.New("tags end without a closing block")
+		}
+	}
+	return tags, nil
+}
+
+// avroBytes reads an Avro byte string from the start of b and returns it with the rest of b.
+func avroBytes(b []byte) ([]byte, []byte, error) {
+	size, n := binary.Varint(b)
+	if n <= 0 || size < 0 || size > int64(len(b)-n) {
+		return nil, nil, errors.New("invalid length")
+	}
+	return b[n : n+int(size)], b[n+int(size):], nil
+}
@@ -0,0 +1,204 @@
+package aogo
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/liteseed/goar/tag"
+	"github.com/liteseed/goar/transaction/data_item"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDataItemID(t *testing.T) {
+	item := data_item.New([]byte("data"), testProcess, "", &[]tag.Tag{{Name: "Action", Value: "Eval"}})
+	assert.NoError(t, item.Sign(setupSigner(t)))
+
+	id, err := DataItemID(item.Raw)
+	assert.NoError(t, err)
+	assert.Equal(t, item.ID, id)
+	assert.Len(t, id, 43)
+
+	_, err = DataItemID(nil)
+	assert.ErrorContains(t, err, "too short")
+	_, err = DataItemID(item.Raw[:100])
+	assert.ErrorContains(t, err, "too short")
+	_, err = DataItemID([]byte{9, 0, 1, 2})
+	assert.ErrorContains(t, err, "unsupported signature type 9")
+}
+
+func TestWithVerifyIDs(t *testing.T) {
+	returned := ""
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		id, err := DataItemID(b)
+		assert.NoError(t, err)
+		if returned != "" {
+			id = returned
+		}
+		_, err = w.Write([]byte(`{"id": "` + id + `"}`))
+		assert.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+	ao, err := New(WithMUURL(srv.URL), WithVerifyIDs(), WithLogger(logger))
+	assert.NoError(t, err)
+	s := setupSigner(t)
+
+	t.Run("Match", func(t *testing.T) {
+		returned = ""
+		_, err := ao.SendMessage(testProcess, "data", nil, "", s)
+		assert.NoError(t, err)
+		_, err = ao.SpawnProcess(testModule, nil, nil, s)
+		assert.NoError(t, err)
+		assert.Empty(t, logs.String())
+	})
+
+	t.Run("Mismatch", func(t *testing.T) {
+		returned = testMessage
+		_, err := ao.SendMessage(testProcess, "data", nil, "", s)
+		assert.ErrorIs(t, err, ErrIDMismatch)
+		assert.ErrorContains(t, err, testMessage)
+		assert.Contains(t, logs.String(), "returned="+testMessage)
+		assert.Contains(t, logs.String(), "signed=")
+
+		_, err = ao.SpawnProcess(testModule, nil, nil, s)
+		assert.ErrorIs(t, err, ErrIDMismatch)
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		returned = testMessage
+		ao, err := New(WithMUURL(srv.URL))
+		assert.NoError(t, err)
+		id, err := ao.SendMessage(testProcess, "data", nil, "", s)
+		assert.NoError(t, err)
+		assert.Equal(t, testMessage, id)
+	})
+}
+
+func TestDecodeDataItem(t *testing.T) {
+	tags := []tag.Tag{{Name: "Action", Value: "Eval"}, {Name: "Data-Protocol", Value: "ao"}}
+	anchor := "00000000000000000000000000000001"
+
+	for name, s := range map[string]Signer{"Arweave": ArweaveSigner(setupSigner(t)), "ED25519": newED25519Signer(t)} {
+		t.Run(name, func(t *testing.T) {
+			item := data_item.New([]byte("data"), testProcess, anchor, &tags)
+			assert.NoError(t, signDataItem(item, s))
+
+			decoded, err := DecodeDataItem(item.Raw)
+			assert.NoError(t, err)
+			assert.Equal(t, item.ID, decoded.ID)
+			assert.Equal(t, s.SignatureType(), decoded.SignatureType)
+			assert.Equal(t, s.Owner(), decoded.Owner)
+			assert.Equal(t, testProcess, decoded.Target)
+			assert.Equal(t, anchor, decoded.Anchor)
+			assert.Equal(t, tags, decoded.Tags)
+			assert.Equal(t, []byte("data"), decoded.Data)
+			assert.Equal(t, item.Raw, decoded.Raw)
+		})
+	}
+
+	t.Run("NoOptionalFields", func(t *testing.T) {
+		item := data_item.New(nil, "", "", &[]tag.Tag{})
+		assert.NoError(t, signDataItem(item, newED25519Signer(t)))
+
+		decoded, err := DecodeDataItem(item.Raw)
+		assert.NoError(t, err)
+		assert.Empty(t, decoded.Target)
+		assert.Empty(t, decoded.Anchor)
+		assert.Empty(t, decoded.Tags)
+		assert.Empty(t, decoded.Data)
+	})
+
+	t.Run("Malformed", func(t *testing.T) {
+		item := data_item.New([]byte("data"), testProcess, anchor, &tags)
+		assert.NoError(t, signDataItem(item, newED25519Signer(t)))
+		// Every truncation that cuts into the header or tags must fail without panicking.
+		header := len(item.Raw) - len("data")
+		for n := 0; n < header; n++ {
+			_, err := DecodeDataItem(item.Raw[:n])
+			assert.ErrorIs(t, err, ErrInvalidDataItem, "truncated to %d bytes", n)
+		}
+
+		_, err := DecodeDataItem([]byte{9, 0, 1, 2})
+		assert.ErrorContains(t, err, "unsupported signature type 9")
+
+		bad := bytes.Clone(item.Raw)
+		bad[2+64+32] = 2
+		_, err = DecodeDataItem(bad)
+		assert.ErrorContains(t, err, "target presence byte is 2")
+
+		bad = bytes.Clone(item.Raw)
+		bad[2+64+32+33+33] = 3
+		_, err = DecodeDataItem(bad)
+		assert.ErrorContains(t, err, "3 tags declared but 2 encoded")
+	})
+}
+
+func TestVerifyDataItem(t *testing.T) {
+	tags := []tag.Tag{{Name: "Action", Value: "Eval"}}
+	for name, s := range map[string]Signer{"Arweave": ArweaveSigner(setupSigner(t)), "ED25519": newED25519Signer(t)} {
+		t.Run(name, func(t *testing.T) {
+			item := data_item.New([]byte("data"), testProcess, "", &tags)
+			assert.NoError(t, signDataItem(item, s))
+			decoded, err := DecodeDataItem(item.Raw)
+			assert.NoError(t, err)
+
+			ok, err := VerifyDataItem(decoded)
+			assert.NoError(t, err)
+			assert.True(t, ok)
+
+			tampered := *decoded
+			tampered.Data = []byte("other")
+			ok, err = VerifyDataItem(&tampered)
+			assert.NoError(t, err)
+			assert.False(t, ok)
+
+			tampered = *decoded
+			tampered.ID = testMessage
+			ok, err = VerifyDataItem(&tampered)
+			assert.NoError(t, err)
+			assert.False(t, ok)
+		})
+	}
+
+	t.Run("OwnerAddress", func(t *testing.T) {
+		s := setupSigner(t)
+		item := data_item.New([]byte("data"), testProcess, "", &tags)
+		assert.NoError(t, item.Sign(s))
+		decoded, err := DecodeDataItem(item.Raw)
+		assert.NoError(t, err)
+
+		ok, err := VerifyDataItem(decoded)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, s.Address, decoded.OwnerAddress())
+	})
+
+	t.Run("Built", func(t *testing.T) {
+		s := newED25519Signer(t)
+		item := data_item.New([]byte("data"), testProcess, "", &tags)
+		assert.NoError(t, signDataItem(item, s))
+		decoded, err := DecodeDataItem(item.Raw)
+		assert.NoError(t, err)
+
+		built := &DataItem{ID: decoded.ID, SignatureType: decoded.SignatureType, Signature: decoded.Signature, Owner: decoded.Owner,
+			Target: decoded.Target, Tags: decoded.Tags, Data: decoded.Data}
+		ok, err := VerifyDataItem(built)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("Unsupported", func(t *testing.T) {
+		_, err := VerifyDataItem(&DataItem{SignatureType: data_item.Ethereum})
+		assert.ErrorContains(t, err, "not supported")
+		_, err = VerifyDataItem(&DataItem{SignatureType: 9})
+		assert.ErrorIs(t, err, ErrInvalidDataItem)
+	})
+}
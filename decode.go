@@ -0,0 +1,104 @@
+package aogo
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// LoadResultAs loads the result of message and unmarshals the data of the outbound message picked by sel into T.
+// A nil sel picks the first message, like ByIndex(0). A result without a matching message yields an error
+// wrapping ErrEmptyResult, and one with several messages matching a selector that expects exactly one an error
+// wrapping ErrAmbiguousMessage.
+func LoadResultAs[T any](ao *AO, process string, message string, sel MessageSelector) (T, *Response, error) {
+	var out T
+	res, err := ao.LoadResult(process, message)
+	if err != nil {
+		return out, nil, err
+	}
+	out, err = decodeMessage[T](res, sel)
+	return out, res, err
+}
+
+// DryRunDecode dry runs msg and unmarshals the data of the outbound message picked by sel into T, like
+// LoadResultAs.
+func DryRunDecode[T any](ao *AO, msg Message, sel MessageSelector) (T, error) {
+	res, err := ao.DryRun(msg)
+	if err != nil {
+		var out T
+		return out, err
+	}
+	return decodeMessage[T](res, sel)
+}
+
+// decodeMessage unmarshals the data of the message of res picked by sel, or the first one if sel is nil, into T.
+func decodeMessage[T any](res *Response, sel MessageSelector) (T, error) {
+	var out T
+	if sel == nil {
+		sel = ByIndex(0)
+	}
+	m, err := sel(res.Messages)
+	if err != nil {
+		return out, err
+	}
+	if err := json.Unmarshal([]byte(m.Data), &out); err != nil {
+		return out, fmt.Errorf("message data is not valid JSON for %T: %w", out, err)
+	}
+	return out, nil
+}
+
+// MessageSelector picks one of the outbound messages of a result, see ByIndex, ByTarget, ByTag, ByReference and
+// Matching.
+type MessageSelector func(messages []ResultMessage) (ResultMessage, error)
+
+// ByIndex selects the outbound message at index i.
+func ByIndex(i int) MessageSelector {
+	return func(messages []ResultMessage) (ResultMessage, error) {
+		if i < 0 || i >= len(messages) {
+			return ResultMessage{}, fmt.Errorf("no message at index %d of %d: %w", i, len(messages), ErrEmptyResult)
+		}
+		return messages[i], nil
+	}
+}
+
+// ByTarget selects the only outbound message to target.
+func ByTarget(target string) MessageSelector {
+	return Matching(fmt.Sprintf("to %s", target), func(m ResultMessage) bool { return m.Target == target })
+}
+
+// ByTag selects the only outbound message with a tag name whose value is value, e.g. ByTag("Action",
+// "Credit-Notice").
+func ByTag(name string, value string) MessageSelector {
+	return Matching(fmt.Sprintf("with %s %s", name, value), func(m ResultMessage) bool {
+		v, ok := m.Tag(name)
+		return ok && v == value
+	})
+}
+
+// ByReference selects the only outbound message replying to the message whose Reference tag is ref, see
+// Response.MessageByReference.
+func ByReference(ref string) MessageSelector {
+	return Matching(fmt.Sprintf("replying to reference %s", ref), func(m ResultMessage) bool {
+		v, ok := m.Tag("X-Reference")
+		return ok && ref != "" && v == ref
+	})
+}
+
+// Matching selects the only outbound message for which match returns true. description names the messages
+// match selects in errors, e.g. "to the treasury".
+func Matching(description string, match func(ResultMessage) bool) MessageSelector {
+	return func(messages []ResultMessage) (ResultMessage, error) {
+		var found []ResultMessage
+		for _, m := range messages {
+			if match(m) {
+				found = append(found, m)
+			}
+		}
+		switch len(found) {
+		case 0:
+			return ResultMessage{}, fmt.Errorf("no message %s: %w", description, ErrEmptyResult)
+		case 1:
+			return found[0], nil
+		}
+		return ResultMessage{}, fmt.Errorf("%w: %d messages %s", ErrAmbiguousMessage, len(found), description)
+	}
+}
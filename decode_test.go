@@ -0,0 +1,106 @@
+package aogo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/liteseed/goar/tag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadResultAs(t *testing.T) {
+	body := `{"Messages": [], "Spawns": [], "Outputs": [], "Error": "", "GasUsed": 0}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(body))
+		assert.NoError(t, err)
+	}))
+	defer srv.Close()
+	ao := &AO{cu: newCU(srv.URL)}
+
+	type info struct {
+		Name   string `json:"name"`
+		Ticker string `json:"ticker"`
+	}
+
+	t.Run("First", func(t *testing.T) {
+		body = `{"Messages": [{"Target": "a", "Data": {"name": "Token", "ticker": "TKN"}}, {"Target": "b", "Data": "{\"name\": \"Other\"}"}]}`
+		out, res, err := LoadResultAs[info](ao, testProcess, testMessage, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, info{Name: "Token", Ticker: "TKN"}, out)
+		assert.Len(t, res.Messages, 2)
+	})
+	t.Run("Pick", func(t *testing.T) {
+		out, _, err := LoadResultAs[info](ao, testProcess, testMessage, ByTarget("b"))
+		assert.NoError(t, err)
+		assert.Equal(t, info{Name: "Other"}, out)
+	})
+	t.Run("NoMessages", func(t *testing.T) {
+		body = `{"Messages": []}`
+		_, res, err := LoadResultAs[info](ao, testProcess, testMessage, nil)
+		assert.ErrorIs(t, err, ErrEmptyResult)
+		assert.NotNil(t, res)
+	})
+	t.Run("NotJSON", func(t *testing.T) {
+		body = `{"Messages": [{"Target": "a", "Data": "Balance: 10"}]}`
+		_, _, err := LoadResultAs[info](ao, testProcess, testMessage, nil)
+		assert.ErrorContains(t, err, "not valid JSON for aogo.info")
+	})
+}
+
+func TestDryRunDecode(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		_, err := w.Write([]byte(`{"Messages": [{"Target": "a", "Data": "[1, 2]"}, {"Target": "b", "Data": "{\"balance\": \"100\"}"}]}`))
+		assert.NoError(t, err)
+	}))
+	defer srv.Close()
+	ao := &AO{cu: newCU(srv.URL)}
+
+	list, err := DryRunDecode[[]int](ao, Message{Target: "process"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, list)
+
+	balances, err := DryRunDecode[map[string]string](ao, Message{Target: "process"}, ByTarget("b"))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"balance": "100"}, balances)
+
+	_, err = DryRunDecode[map[string]string](ao, Message{Target: "process"}, ByIndex(0))
+	assert.ErrorContains(t, err, "not valid JSON for map[string]string")
+}
+
+func TestMessageSelector(t *testing.T) {
+	messages := []ResultMessage{
+		{Target: "a", Tags: []tag.Tag{{Name: "Action", Value: "Debit-Notice"}}, Data: "1"},
+		{Target: "b", Tags: []tag.Tag{{Name: "Action", Value: "Credit-Notice"}}, Data: "2"},
+		{Target: "b", Tags: []tag.Tag{{Name: "Action", Value: "Log"}, {Name: "X-Reference", Value: "3"}}, Data: "3"},
+	}
+
+	for name, tc := range map[string]struct {
+		sel  MessageSelector
+		want string
+	}{
+		"Index":     {ByIndex(2), "3"},
+		"Target":    {ByTarget("a"), "1"},
+		"Tag":       {ByTag("Action", "Credit-Notice"), "2"},
+		"Reference": {ByReference("3"), "3"},
+		"Matching":  {Matching("logged", func(m ResultMessage) bool { return m.Data == "3" }), "3"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			m, err := tc.sel(messages)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, m.Data)
+		})
+	}
+
+	_, err := ByTarget("b")(messages)
+	assert.ErrorIs(t, err, ErrAmbiguousMessage)
+	assert.ErrorContains(t, err, "2 messages to b")
+
+	_, err = ByTarget("c")(messages)
+	assert.ErrorIs(t, err, ErrEmptyResult)
+	_, err = ByIndex(3)(messages)
+	assert.ErrorIs(t, err, ErrEmptyResult)
+	_, err = ByReference("")(messages)
+	assert.ErrorIs(t, err, ErrEmptyResult)
+}
@@ -0,0 +1,86 @@
+package aogo
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Environment variables read by NewFromEnv.
+const (
+	EnvCUURL      = "AO_CU_URL"
+	EnvMUURL      = "AO_MU_URL"
+	EnvSUURL      = "AO_SU_URL"
+	EnvGatewayURL = "AO_GATEWAY_URL"
+	// EnvWallet holds either the JSON of an Arweave JWK or the path of a file containing it.
+	EnvWallet = "AO_WALLET"
+)
+
+// NewFromEnv returns an AO client configured from the environment: the unit URLs from AO_CU_URL, AO_MU_URL,
+// AO_SU_URL and AO_GATEWAY_URL, keeping the defaults of New for any that is unset, and the default signer from
+// AO_WALLET, which is required. options are applied after the environment and so take precedence.
+// All invalid or missing variables are reported together.
+func NewFromEnv(options ...Option) (*AO, error) {
+	var env []Option
+	var errs []error
+	for _, v := range []struct {
+		name   string
+		option func(string) Option
+	}{
+		{EnvCUURL, WithCUURL},
+		{EnvMUURL, WithMUURL},
+		{EnvSUURL, WithSUURL},
+		{EnvGatewayURL, WithGatewayURL},
+	} {
+		u := strings.TrimSpace(os.Getenv(v.name))
+		if u == "" {
+			continue
+		}
+		if err := validateURL(u); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", v.name, err))
+			continue
+		}
+		env = append(env, v.option(u))
+	}
+
+	jwk, err := walletFromEnv()
+	if err != nil {
+		errs = append(errs, err)
+	} else {
+		env = append(env, WithSignerBytes(jwk))
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid environment: %w", errors.Join(errs...))
+	}
+	return New(append(env, options...)...)
+}
+
+// walletFromEnv returns the JWK held by or referenced by AO_WALLET.
+func walletFromEnv() ([]byte, error) {
+	wallet := strings.TrimSpace(os.Getenv(EnvWallet))
+	if wallet == "" {
+		return nil, fmt.Errorf("%s is not set", EnvWallet)
+	}
+	if strings.HasPrefix(wallet, "{") {
+		return []byte(wallet), nil
+	}
+	jwk, err := os.ReadFile(wallet)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", EnvWallet, err)
+	}
+	return jwk, nil
+}
+
+// validateURL checks that u is an absolute http or https URL.
+func validateURL(u string) error {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return err
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("%q is not an http or https URL", u)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package aogo
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewFromEnv(t *testing.T) {
+	t.Run("Defaults", func(t *testing.T) {
+		t.Setenv(EnvWallet, "./keys/wallet.json")
+		ao, err := NewFromEnv()
+		assert.NoError(t, err)
+		assert.Equal(t, Legacynet.CUURL, ao.cu.url)
+		assert.Equal(t, Legacynet.MUURL, ao.mu.url)
+		assert.Equal(t, Legacynet.SUURL, ao.su.url)
+		assert.Equal(t, Legacynet.GatewayURL, ao.gateway.url)
+		assert.Equal(t, setupSigner(t).Address, ao.signer.Address)
+	})
+
+	t.Run("URLs", func(t *testing.T) {
+		t.Setenv(EnvWallet, "./keys/wallet.json")
+		t.Setenv(EnvCUURL, "http://cu.internal")
+		t.Setenv(EnvMUURL, "https://mu.internal")
+		t.Setenv(EnvSUURL, "http://su.internal")
+		t.Setenv(EnvGatewayURL, "https://gateway.internal")
+		ao, err := NewFromEnv(WithMUURL("http://override"))
+		assert.NoError(t, err)
+		assert.Equal(t, "http://cu.internal", ao.cu.url)
+		assert.Equal(t, "http://override", ao.mu.url)
+		assert.Equal(t, "http://su.internal", ao.su.url)
+		assert.Equal(t, "https://gateway.internal", ao.gateway.url)
+	})
+
+	t.Run("InlineWallet", func(t *testing.T) {
+		jwk, err := os.ReadFile("./keys/wallet.json")
+		assert.NoError(t, err)
+		t.Setenv(EnvWallet, string(jwk))
+		ao, err := NewFromEnv()
+		assert.NoError(t, err)
+		assert.Equal(t, setupSigner(t).Address, ao.signer.Address)
+	})
+
+	t.Run("MissingWallet", func(t *testing.T) {
+		t.Setenv(EnvWallet, "")
+		_, err := NewFromEnv()
+		assert.ErrorContains(t, err, "AO_WALLET is not set")
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Setenv(EnvWallet, "./keys/missing.json")
+		t.Setenv(EnvCUURL, "cu.internal")
+		t.Setenv(EnvGatewayURL, "ftp://gateway.internal")
+		_, err := NewFromEnv()
+		assert.ErrorContains(t, err, "AO_CU_URL")
+		assert.ErrorContains(t, err, "AO_GATEWAY_URL")
+		assert.ErrorContains(t, err, "AO_WALLET")
+	})
+
+	t.Run("InvalidWallet", func(t *testing.T) {
+		t.Setenv(EnvWallet, `{"kty": "RSA", "d": "super-secret"}`)
+		_, err := NewFromEnv()
+		assert.ErrorIs(t, err, ErrInvalidSigner)
+		assert.NotContains(t, err.Error(), "super-secret")
+	})
+}
@@ -0,0 +1,159 @@
+package aogo
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+// Unit identifies the AO unit a request was sent to.
+type Unit string
+
+const (
+	UnitCU Unit = "cu"
+	UnitMU Unit = "mu"
+	UnitSU Unit = "su"
+
+	UnitGateway Unit = "gateway"
+)
+
+var (
+	// ErrMonitorNotFound is returned by Unmonitor when the process has no active monitor.
+	ErrMonitorNotFound = errors.New("monitor not found")
+	// ErrProcessNotFound matches an AOError for a 404 answered by the CU or SU, which look up every request by process.
+	ErrProcessNotFound = errors.New("process not found")
+	// ErrInvalidSigner is returned when a request that must be signed is given no usable signer.
+	ErrInvalidSigner = errors.New("invalid signer")
+	// ErrServerError matches an AOError for a 5xx response.
+	ErrServerError = errors.New("server error")
+	// ErrInvalidID is returned before any request is made when a process, module or message ID is malformed.
+	ErrInvalidID = errors.New("invalid id")
+	// ErrInvalidQuantity is returned by Transfer when the quantity is not a positive integer.
+	ErrInvalidQuantity = errors.New("invalid quantity")
+	// ErrEmptyResult is returned when a unit answers successfully but without a result.
+	ErrEmptyResult = errors.New("empty result")
+	// ErrCircuitOpen is returned without a request when the circuit breaker set by WithCircuitBreaker has opened
+	// the circuit of the unit URL after repeated failures.
+	ErrCircuitOpen = errors.New("circuit open")
+	// ErrIDMismatch is returned when WithVerifyIDs is set and the MU answers with an ID other than the one of the
+	// data item the client signed.
+	ErrIDMismatch = errors.New("id mismatch")
+	// ErrSignatureRejected matches an AOError for a 4xx answered by the MU that blames the signature of the data
+	// item, for example because the process does not accept its signature type.
+	ErrSignatureRejected = errors.New("signature rejected")
+	// ErrTooManyTags is returned before a data item is signed when it would carry more tags than ANS-104 allows.
+	ErrTooManyTags = errors.New("too many tags")
+	// ErrTagTooLarge is returned before a data item is signed when a tag name or value is longer than ANS-104 allows.
+	ErrTagTooLarge = errors.New("tag too large")
+	// ErrInvalidDataItem is returned by DecodeDataItem when the bytes are not a well-formed ANS-104 data item.
+	ErrInvalidDataItem = errors.New("invalid data item")
+	// ErrAmbiguousMessage is returned by LoadResultAs and DryRunDecode when several outbound messages match a
+	// selector that expects exactly one.
+	ErrAmbiguousMessage = errors.New("ambiguous message")
+	// ErrNoConsensus is returned by LoadResultConsensus when fewer compute units than the quorum return the same
+	// result.
+	ErrNoConsensus = errors.New("no consensus")
+	// ErrClosed is returned when a background task is started on a client after Close.
+	ErrClosed = errors.New("client closed")
+	// ErrDataItemTooLarge is returned without a request when a data item exceeds the size set by
+	// WithMaxDataItemSize.
+	ErrDataItemTooLarge = errors.New("data item too large")
+	// ErrResponseTooLarge is returned when a response body exceeds the size the client is willing to buffer.
+	ErrResponseTooLarge = errors.New("response too large")
+)
+
+// AOError is returned when a unit answers with a non-2xx status.
+type AOError struct {
+	Unit       Unit
+	StatusCode int
+	Status     string
+	Server     string
+	Body       string
+}
+
+// DefaultMaxErrorBody is how many bytes of a response body errors keep unless set with WithMaxErrorBody.
+const DefaultMaxErrorBody = 4 << 10
+
+func newAOError(unit Unit, resp *http.Response, body []byte, limit int) *AOError {
+	return &AOError{
+		Unit:       unit,
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Server:     resp.Request.URL.Host,
+		Body:       truncateBody(body, limit),
+	}
+}
+
+// unmarshalError reports that the response body could not be decoded, quoting the start of body.
+func unmarshalError(what string, err error, body []byte, limit int) error {
+	return fmt.Errorf("failed to unmarshal %s: %v, body: %s", what, err, truncateBody(body, limit))
+}
+
+// truncateBody returns body cut to at most limit bytes, on a UTF-8 boundary, with a note of how much was cut.
+// A zero limit means DefaultMaxErrorBody and a negative one keeps the whole body.
+func truncateBody(body []byte, limit int) string {
+	if limit == 0 {
+		limit = DefaultMaxErrorBody
+	}
+	if limit < 0 || len(body) <= limit {
+		return string(body)
+	}
+	n := limit
+	for n > 0 && !utf8.RuneStart(body[n]) {
+		n--
+	}
+	return fmt.Sprintf("%s... (%d bytes truncated)", body[:n], len(body)-n)
+}
+
+func (e *AOError) Error() string {
+	return fmt.Sprintf("%s request failed with status: %s, code: %d, server: %s, body: %s", e.Unit, e.Status, e.StatusCode, e.Server, e.Body)
+}
+
+// Is lets errors.Is match an AOError against ErrServerError, ErrProcessNotFound and ErrSignatureRejected.
+func (e *AOError) Is(target error) bool {
+	switch target {
+	case ErrServerError:
+		return e.StatusCode >= http.StatusInternalServerError
+	case ErrProcessNotFound:
+		return e.StatusCode == http.StatusNotFound && (e.Unit == UnitCU || e.Unit == UnitSU)
+	case ErrSignatureRejected:
+		return e.Unit == UnitMU && e.StatusCode >= http.StatusBadRequest && e.StatusCode < http.StatusInternalServerError &&
+			strings.Contains(strings.ToLower(e.Body), "signature")
+	}
+	return false
+}
+
+// ProcessError is returned when a unit evaluated a message but the process itself failed, for example
+// with a Lua runtime error. The unit was reachable, so it is never a transport error.
+type ProcessError struct {
+	// Message is the raw Error field of the result.
+	Message string
+	// GasUsed is the gas spent up to the failure.
+	GasUsed Gas
+}
+
+func (e *ProcessError) Error() string {
+	return fmt.Sprintf("process error: %s", e.Message)
+}
+
+// IsProcessError reports whether err is, or wraps, a ProcessError.
+func IsProcessError(err error) bool {
+	var pErr *ProcessError
+	return errors.As(err, &pErr)
+}
+
+// IsTransportError reports whether err means a unit could not be reached or answered with a non-2xx status,
+// as opposed to a process failing while the unit evaluated it.
+func IsTransportError(err error) bool {
+	if err == nil || IsProcessError(err) {
+		return false
+	}
+	var aoErr *AOError
+	var urlErr *url.Error
+	var netErr net.Error
+	return errors.As(err, &aoErr) || errors.As(err, &urlErr) || errors.As(err, &netErr)
+}
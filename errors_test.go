@@ -0,0 +1,194 @@
+package aogo
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/liteseed/goar/signer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAOError(t *testing.T) {
+	t.Run("CU", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			_, err := w.Write([]byte("process not found"))
+			assert.NoError(t, err)
+		}))
+		defer srv.Close()
+
+		ao := &AO{cu: newCU(srv.URL)}
+
+		_, err := ao.LoadResult(testProcess, testMessage)
+		var aoErr *AOError
+		assert.True(t, errors.As(err, &aoErr))
+		assert.Equal(t, UnitCU, aoErr.Unit)
+		assert.Equal(t, http.StatusNotFound, aoErr.StatusCode)
+		assert.Equal(t, "process not found", aoErr.Body)
+
+		_, err = ao.DryRun(Message{Target: "process"})
+		assert.True(t, errors.As(err, &aoErr))
+		assert.Equal(t, http.StatusNotFound, aoErr.StatusCode)
+	})
+
+	t.Run("MU", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err := w.Write([]byte("server down"))
+			assert.NoError(t, err)
+		}))
+		defer srv.Close()
+
+		ao := &AO{mu: newMU(srv.URL)}
+		ao.mu.retry = NoRetry
+		s, err := signer.FromPath("./keys/wallet.json")
+		assert.NoError(t, err)
+
+		_, err = ao.SendMessage(testProcess, "data", nil, "", s)
+		var aoErr *AOError
+		assert.True(t, errors.As(err, &aoErr))
+		assert.Equal(t, UnitMU, aoErr.Unit)
+		assert.Equal(t, http.StatusInternalServerError, aoErr.StatusCode)
+		assert.Equal(t, "server down", aoErr.Body)
+	})
+}
+
+func TestProcessError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"Messages": [], "Spawns": [], "Outputs": [], "Error": "[string \"aos\"]:1: boom", "GasUsed": 42}`))
+		assert.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	ao := &AO{cu: newCU(srv.URL)}
+
+	_, err := ao.LoadResult(testProcess, testMessage)
+	var pErr *ProcessError
+	assert.True(t, errors.As(err, &pErr))
+	assert.Equal(t, `[string "aos"]:1: boom`, pErr.Message)
+	assert.Equal(t, Gas(42), pErr.GasUsed)
+	assert.True(t, IsProcessError(err))
+	assert.False(t, IsTransportError(err))
+
+	_, err = ao.DryRun(Message{Target: "process"})
+	assert.True(t, IsProcessError(err))
+}
+
+func TestIsTransportError(t *testing.T) {
+	t.Run("Status", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer srv.Close()
+
+		ao := &AO{cu: newCU(srv.URL)}
+		_, err := ao.LoadResult(testProcess, testMessage)
+		assert.True(t, IsTransportError(err))
+		assert.False(t, IsProcessError(err))
+	})
+	t.Run("Unreachable", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		srv.Close()
+
+		ao := &AO{cu: newCU(srv.URL)}
+		ao.cu.retry = NoRetry
+		_, err := ao.LoadResult(testProcess, testMessage)
+		assert.True(t, IsTransportError(err))
+	})
+	t.Run("Other", func(t *testing.T) {
+		assert.False(t, IsTransportError(nil))
+		assert.False(t, IsTransportError(errors.New("signer is required")))
+	})
+}
+
+func TestSentinelErrors(t *testing.T) {
+	t.Run("InvalidSigner", func(t *testing.T) {
+		ao := &AO{mu: newMU("http://localhost")}
+		_, err := ao.SendMessage(testProcess, "data", nil, "", nil)
+		assert.ErrorIs(t, err, ErrInvalidSigner)
+		_, err = ao.SpawnProcess(testModule, nil, nil, nil)
+		assert.ErrorIs(t, err, ErrInvalidSigner)
+		_, err = ao.Monitor("process", nil)
+		assert.ErrorIs(t, err, ErrInvalidSigner)
+	})
+	t.Run("Status", func(t *testing.T) {
+		status := http.StatusNotFound
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+		}))
+		defer srv.Close()
+
+		ao := &AO{cu: newCU(srv.URL), su: newSU(srv.URL)}
+		ao.cu.retry = NoRetry
+		_, err := ao.LoadResult(testProcess, testMessage)
+		assert.ErrorIs(t, err, ErrProcessNotFound)
+		assert.NotErrorIs(t, err, ErrServerError)
+		_, err = ao.GetMessages("process", "", "")
+		assert.ErrorIs(t, err, ErrProcessNotFound)
+
+		status = http.StatusInternalServerError
+		_, err = ao.LoadResult(testProcess, testMessage)
+		assert.ErrorIs(t, err, ErrServerError)
+		assert.NotErrorIs(t, err, ErrProcessNotFound)
+	})
+	t.Run("EmptyResult", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		defer srv.Close()
+
+		ao := &AO{cu: newCU(srv.URL)}
+		_, err := ao.LoadResult(testProcess, testMessage)
+		assert.ErrorIs(t, err, ErrEmptyResult)
+		_, err = ao.DryRun(Message{Target: "process"})
+		assert.ErrorIs(t, err, ErrEmptyResult)
+	})
+}
+
+func TestErrorBodyTruncation(t *testing.T) {
+	body := strings.Repeat("x", 10000)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/result/"+testModule {
+			_, err := w.Write([]byte("<html>" + body))
+			assert.NoError(t, err)
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte(body))
+		assert.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	t.Run("Default", func(t *testing.T) {
+		ao, err := New(WithCUURL(srv.URL))
+		assert.NoError(t, err)
+		_, err = ao.LoadResult(testProcess, testMessage)
+		var aoErr *AOError
+		assert.True(t, errors.As(err, &aoErr))
+		assert.Equal(t, body[:DefaultMaxErrorBody]+"... (5904 bytes truncated)", aoErr.Body)
+
+		_, err = ao.LoadResult(testProcess, testModule)
+		assert.ErrorContains(t, err, "body: <html>xxx")
+		assert.ErrorContains(t, err, "(5910 bytes truncated)")
+	})
+	t.Run("Configured", func(t *testing.T) {
+		ao, err := New(WithCUURL(srv.URL), WithMaxErrorBody(10))
+		assert.NoError(t, err)
+		_, err = ao.LoadResult(testProcess, testMessage)
+		var aoErr *AOError
+		assert.True(t, errors.As(err, &aoErr))
+		assert.Equal(t, "xxxxxxxxxx... (9990 bytes truncated)", aoErr.Body)
+	})
+	t.Run("Unlimited", func(t *testing.T) {
+		ao, err := New(WithCUURL(srv.URL), WithMaxErrorBody(-1))
+		assert.NoError(t, err)
+		_, err = ao.LoadResult(testProcess, testMessage)
+		var aoErr *AOError
+		assert.True(t, errors.As(err, &aoErr))
+		assert.Equal(t, body, aoErr.Body)
+	})
+	t.Run("UTF8", func(t *testing.T) {
+		assert.Equal(t, "ab... (4 bytes truncated)", truncateBody([]byte("ab€x"), 3))
+	})
+}
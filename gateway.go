@@ -0,0 +1,207 @@
+package aogo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/liteseed/goar/tag"
+)
+
+const transactionQuery = `query ($ids: [ID!]) {
+  transactions(ids: $ids) {
+    edges {
+      node {
+        id
+        anchor
+        recipient
+        owner { address key }
+        tags { name value }
+        data { size type }
+        block { id height timestamp }
+      }
+    }
+  }
+}`
+
+const pushedQuery = `query ($process: [String!]!, $pushedFor: [String!]!) {
+  transactions(tags: [{name: "From-Process", values: $process}, {name: "Pushed-For", values: $pushedFor}], first: 100) {
+    edges {
+      node {
+        id
+        anchor
+        recipient
+        owner { address key }
+        tags { name value }
+        data { size type }
+        block { id height timestamp }
+      }
+    }
+  }
+}`
+
+type Gateway struct {
+	client       *http.Client
+	url          string
+	timeout      time.Duration
+	retry        RetryPolicy
+	logger       *slog.Logger
+	observer     Observer
+	breaker      *circuitBreaker
+	maxErrorBody int
+	// maxResponseBytes caps buffered response bodies, see WithMaxResponseBytes.
+	maxResponseBytes int64
+	// header is added to every request.
+	header http.Header
+}
+
+func newGateway(url string) Gateway {
+	return Gateway{
+		client: http.DefaultClient,
+		url:    url,
+		retry:  DefaultRetryPolicy,
+	}
+}
+
+type TransactionOwner struct {
+	Address string `json:"address"`
+	Key     string `json:"key"`
+}
+
+type TransactionData struct {
+	Size string `json:"size"`
+	Type string `json:"type"`
+}
+
+type TransactionBlock struct {
+	ID        string `json:"id"`
+	Height    int64  `json:"height"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// Transaction is a transaction or data item as indexed by the gateway.
+// Block is nil until the transaction has been included in a block.
+type Transaction struct {
+	ID        string            `json:"id"`
+	Anchor    string            `json:"anchor"`
+	Recipient string            `json:"recipient"`
+	Owner     TransactionOwner  `json:"owner"`
+	Tags      []tag.Tag         `json:"tags"`
+	Data      TransactionData   `json:"data"`
+	Block     *TransactionBlock `json:"block"`
+}
+
+type graphQLRequest struct {
+	Query     string         `json:"query"`
+	Variables map[string]any `json:"variables,omitempty"`
+}
+
+type graphQLError struct {
+	Message string `json:"message"`
+}
+
+type transactionsResponse struct {
+	Data struct {
+		Transactions struct {
+			Edges []struct {
+				Node Transaction `json:"node"`
+			} `json:"edges"`
+		} `json:"transactions"`
+	} `json:"data"`
+	Errors []graphQLError `json:"errors"`
+}
+
+// GetTransaction looks up a transaction by id through the gateway's GraphQL endpoint.
+func (g *Gateway) GetTransaction(id string) (*Transaction, error) {
+	txs, err := g.transactionsByID(context.Background(), id)
+	if err != nil {
+		return nil, err
+	}
+	if len(txs) == 0 {
+		return nil, fmt.Errorf("transaction %s not found", id)
+	}
+	return &txs[0], nil
+}
+
+// pushedMessages looks up the messages the MU pushed from the outbox of process while handling any of pushedFor.
+func (g *Gateway) pushedMessages(process string, pushedFor []string) ([]Transaction, error) {
+	return g.transactions(context.Background(), pushedQuery, map[string]any{"process": []string{process}, "pushedFor": pushedFor})
+}
+
+// transactionsByID looks up the transaction id, returning an empty slice if the gateway has not indexed it.
+func (g *Gateway) transactionsByID(ctx context.Context, id string) ([]Transaction, error) {
+	return g.transactions(ctx, transactionQuery, map[string]any{"ids": []string{id}})
+}
+
+// transactions runs a transactions query and returns the matching transactions.
+func (g *Gateway) transactions(ctx context.Context, query string, variables map[string]any) ([]Transaction, error) {
+	body, err := json.Marshal(graphQLRequest{Query: query, Variables: variables})
+	if err != nil {
+		return nil, err
+	}
+	b, err := g.graphQL(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+
+	var res transactionsResponse
+	err = json.Unmarshal(b, &res)
+	if err != nil {
+		return nil, unmarshalError("response", err, b, g.maxErrorBody)
+	}
+	if len(res.Errors) > 0 {
+		return nil, fmt.Errorf("graphql error: %s", res.Errors[0].Message)
+	}
+	txs := make([]Transaction, 0, len(res.Data.Transactions.Edges))
+	for _, e := range res.Data.Transactions.Edges {
+		txs = append(txs, e.Node)
+	}
+	return txs, nil
+}
+
+// graphQL posts a query to the gateway and returns the raw response body.
+func (g *Gateway) graphQL(ctx context.Context, body []byte) ([]byte, error) {
+	b, err := g.post(ctx, "/graphql", body)
+	if err != nil {
+		return nil, fmt.Errorf("graphql: %w", err)
+	}
+	return b, nil
+}
+
+// post sends body as JSON to path on the gateway, retrying according to the gateway retry policy, and returns
+// the response body. Responses with an error status are returned as an AOError.
+func (g *Gateway) post(ctx context.Context, path string, body []byte) ([]byte, error) {
+	ctx, cancel := requestContext(ctx, g.timeout)
+	defer cancel()
+	resp, err := g.breaker.do(g.url, func() (*http.Response, error) {
+		return g.retry.do(ctx, g.roundTrip, func() (*http.Request, error) {
+			req, err := http.NewRequestWithContext(ctx, "POST", g.url+path, bytes.NewReader(body))
+			if err != nil {
+				return nil, err
+			}
+			req.Header.Set("content-type", "application/json")
+			req.Header.Set("accept", "application/json")
+			return req, nil
+		}, retryGatewayError)
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	b, err := readBody(resp.Body, g.maxResponseBytes)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, newAOError(UnitGateway, resp, b, g.maxErrorBody)
+	}
+	return b, nil
+}
+
+func (g *Gateway) roundTrip(req *http.Request) (*http.Response, error) {
+	return sendRequest(g.client, g.logger, g.observer, UnitGateway, g.header, req)
+}
@@ -0,0 +1,77 @@
+package aogo
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetTransaction(t *testing.T) {
+	t.Run("Found", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "/graphql", r.URL.Path)
+			var req graphQLRequest
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			assert.Equal(t, []any{"txID"}, req.Variables["ids"])
+			_, err := w.Write([]byte(`{"data": {"transactions": {"edges": [{"node": {
+				"id": "txID",
+				"owner": {"address": "ownerAddress"},
+				"tags": [{"name": "Type", "value": "Process"}],
+				"data": {"size": "1984", "type": ""},
+				"block": {"id": "blockID", "height": 1400000, "timestamp": 1715000000}
+			}}]}}}`))
+			assert.NoError(t, err)
+		}))
+		defer srv.Close()
+
+		ao := &AO{gateway: newGateway(srv.URL)}
+		tx, err := ao.GetTransaction("txID")
+		assert.NoError(t, err)
+		assert.Equal(t, "txID", tx.ID)
+		assert.Equal(t, "ownerAddress", tx.Owner.Address)
+		assert.Equal(t, "Process", tx.Tags[0].Value)
+		assert.Equal(t, "1984", tx.Data.Size)
+		assert.Equal(t, int64(1400000), tx.Block.Height)
+	})
+
+	t.Run("Pending", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, err := w.Write([]byte(`{"data": {"transactions": {"edges": [{"node": {"id": "txID", "block": null}}]}}}`))
+			assert.NoError(t, err)
+		}))
+		defer srv.Close()
+
+		ao := &AO{gateway: newGateway(srv.URL)}
+		tx, err := ao.GetTransaction("txID")
+		assert.NoError(t, err)
+		assert.Nil(t, tx.Block)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, err := w.Write([]byte(`{"data": {"transactions": {"edges": []}}}`))
+			assert.NoError(t, err)
+		}))
+		defer srv.Close()
+
+		ao := &AO{gateway: newGateway(srv.URL)}
+		_, err := ao.GetTransaction("txID")
+		assert.Error(t, err)
+	})
+
+	t.Run("GraphQLError", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, err := w.Write([]byte(`{"errors": [{"message": "bad query"}]}`))
+			assert.NoError(t, err)
+		}))
+		defer srv.Close()
+
+		ao := &AO{gateway: newGateway(srv.URL)}
+		_, err := ao.GetTransaction("txID")
+		assert.ErrorContains(t, err, "bad query")
+	})
+}
@@ -5,6 +5,7 @@ go 1.22.1
 require (
 	github.com/liteseed/goar v0.3.0
 	github.com/stretchr/testify v1.9.0
+	golang.org/x/time v0.5.0
 )
 
 require (
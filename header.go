@@ -0,0 +1,21 @@
+package aogo
+
+import "net/http"
+
+// DefaultUserAgent identifies this SDK in requests unless replaced with WithUserAgent.
+const DefaultUserAgent = SDK + "/" + Version
+
+// applyHeaders hands the User-Agent and the headers set by options to every unit.
+func (ao *AO) applyHeaders() {
+	header := ao.headers.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	if ao.userAgent != "" && header.Get("User-Agent") == "" {
+		header.Set("User-Agent", ao.userAgent)
+	}
+	ao.cu.header = header
+	ao.mu.header = header
+	ao.su.header = header
+	ao.gateway.header = header
+}
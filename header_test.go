@@ -0,0 +1,37 @@
+package aogo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeaders(t *testing.T) {
+	var got http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		_, err := w.Write([]byte(`{"Messages": []}`))
+		assert.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	t.Run("Default", func(t *testing.T) {
+		ao, err := New(WithCUURL(srv.URL))
+		assert.NoError(t, err)
+		_, err = ao.LoadResult(testProcess, testMessage)
+		assert.NoError(t, err)
+		assert.Equal(t, "aogo/"+Version, got.Get("User-Agent"))
+	})
+	t.Run("Configured", func(t *testing.T) {
+		ao, err := New(WithCUURL(srv.URL), WithUserAgent("service/1.2"), WithHeader("X-Api-Key", "key"), WithHeader("X-Tenant", "a"), WithHeader("X-Tenant", "b"), WithHeader("Content-Type", "text/plain"))
+		assert.NoError(t, err)
+		_, err = ao.DryRun(Message{Target: testProcess})
+		assert.NoError(t, err)
+		assert.Equal(t, "service/1.2", got.Get("User-Agent"))
+		assert.Equal(t, "key", got.Get("X-Api-Key"))
+		assert.Equal(t, []string{"a", "b"}, got.Values("X-Tenant"))
+		assert.Equal(t, "application/json", got.Get("Content-Type"))
+	})
+}
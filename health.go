@@ -0,0 +1,131 @@
+package aogo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// PingCU checks that the CU answers its root endpoint with a 2xx status. With several CUs configured
+// it succeeds as soon as one of them does.
+func (ao *AO) PingCU(ctx context.Context) error {
+	_, err := ao.cu.root(ctx)
+	return err
+}
+
+// PingMU checks that the MU answers its root endpoint with a 2xx status.
+func (ao *AO) PingMU(ctx context.Context) error {
+	_, err := ao.mu.root(ctx)
+	return err
+}
+
+// HealthCheck pings the CU and the MU concurrently and joins their failures, so it is nil only when both are healthy.
+func (ao *AO) HealthCheck(ctx context.Context) error {
+	var wg sync.WaitGroup
+	var cuErr, muErr error
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		cuErr = ao.PingCU(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		muErr = ao.PingMU(ctx)
+	}()
+	wg.Wait()
+	return errors.Join(cuErr, muErr)
+}
+
+// UnitInfo is the self description a CU or MU serves at its root endpoint.
+type UnitInfo struct {
+	// Address is the wallet address the unit signs with.
+	Address string
+	// Version is the unit software version, if reported.
+	Version string
+	// Timestamp is the unit's clock in milliseconds, if reported.
+	Timestamp int64
+	// Raw is the complete JSON document, for fields not covered above.
+	Raw json.RawMessage
+}
+
+func (i *UnitInfo) UnmarshalJSON(b []byte) error {
+	var info struct {
+		Address   string    `json:"address"`
+		Version   string    `json:"version"`
+		Timestamp flexInt64 `json:"timestamp"`
+	}
+	if err := json.Unmarshal(b, &info); err != nil {
+		return err
+	}
+	*i = UnitInfo{Address: info.Address, Version: info.Version, Timestamp: int64(info.Timestamp), Raw: append(json.RawMessage{}, b...)}
+	return nil
+}
+
+// CUInfo fetches the self description of the CU.
+func (ao *AO) CUInfo() (*UnitInfo, error) {
+	b, err := ao.cu.root(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	return unitInfo(b, ao.cu.maxErrorBody)
+}
+
+// MUInfo fetches the self description of the MU. Its Address is the wallet that signs pushed messages,
+// which processes can use to trust the MU.
+func (ao *AO) MUInfo() (*UnitInfo, error) {
+	b, err := ao.mu.root(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	return unitInfo(b, ao.mu.maxErrorBody)
+}
+
+func unitInfo(b []byte, maxErrorBody int) (*UnitInfo, error) {
+	var info UnitInfo
+	if err := json.Unmarshal(b, &info); err != nil {
+		return nil, unmarshalError("unit info", err, b, maxErrorBody)
+	}
+	return &info, nil
+}
+
+// root fetches the body of the CU root endpoint, which describes the unit.
+func (cu *CU) root(ctx context.Context) ([]byte, error) {
+	ctx, cancel := requestContext(ctx, cu.timeout)
+	defer cancel()
+	resp, b, err := cu.do(ctx, "GET", "/", nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("ping cu: %w", err)
+	}
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, fmt.Errorf("ping cu: %w", newAOError(UnitCU, resp, b, cu.maxErrorBody))
+	}
+	return b, nil
+}
+
+// root fetches the body of the MU root endpoint, which describes the unit. It bypasses the rate limiter,
+// which only paces submissions.
+func (mu *MU) root(ctx context.Context) ([]byte, error) {
+	ctx, cancel := requestContext(ctx, mu.timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "GET", mu.url+"/", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("accept", "application/json")
+	resp, err := mu.roundTrip(req)
+	if err != nil {
+		return nil, fmt.Errorf("ping mu: %w", err)
+	}
+	defer resp.Body.Close()
+	b, err := readBody(resp.Body, mu.maxResponseBytes)
+	if err != nil {
+		return nil, fmt.Errorf("ping mu: %w", err)
+	}
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, fmt.Errorf("ping mu: %w", newAOError(UnitMU, resp, b, mu.maxErrorBody))
+	}
+	return b, nil
+}
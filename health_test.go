@@ -0,0 +1,96 @@
+package aogo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthCheck(t *testing.T) {
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/", r.URL.Path)
+		_, err := w.Write([]byte(`{"address": "unit"}`))
+		assert.NoError(t, err)
+	}))
+	defer healthy.Close()
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+
+	t.Run("Healthy", func(t *testing.T) {
+		ao, err := New(WithCUURL(healthy.URL), WithMUURL(healthy.URL))
+		assert.NoError(t, err)
+		assert.NoError(t, ao.PingCU(context.Background()))
+		assert.NoError(t, ao.PingMU(context.Background()))
+		assert.NoError(t, ao.HealthCheck(context.Background()))
+	})
+	t.Run("Failover", func(t *testing.T) {
+		ao, err := New(WithCUURLs([]string{failing.URL, healthy.URL}))
+		assert.NoError(t, err)
+		assert.NoError(t, ao.PingCU(context.Background()))
+	})
+	t.Run("Unhealthy", func(t *testing.T) {
+		ao, err := New(WithCUURL(failing.URL), WithMUURL(failing.URL))
+		assert.NoError(t, err)
+		err = ao.HealthCheck(context.Background())
+		assert.ErrorContains(t, err, "ping cu")
+		assert.ErrorContains(t, err, "ping mu")
+		assert.ErrorIs(t, err, ErrServerError)
+	})
+	t.Run("Canceled", func(t *testing.T) {
+		ao, err := New(WithCUURL(healthy.URL), WithMUURL(healthy.URL))
+		assert.NoError(t, err)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		assert.ErrorIs(t, ao.PingMU(ctx), context.Canceled)
+		assert.ErrorIs(t, ao.PingCU(ctx), context.Canceled)
+	})
+}
+
+func TestUnitInfo(t *testing.T) {
+	cuServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"version": "2.0.0", "timestamp": 1717431046567, "address": "cuAddress", "processCheckpointTrustedOwners": ["owner"]}`))
+		assert.NoError(t, err)
+	}))
+	defer cuServer.Close()
+	muServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"address": "muAddress", "timestamp": "1717431046567"}`))
+		assert.NoError(t, err)
+	}))
+	defer muServer.Close()
+
+	ao, err := New(WithCUURL(cuServer.URL), WithMUURL(muServer.URL))
+	assert.NoError(t, err)
+
+	cu, err := ao.CUInfo()
+	assert.NoError(t, err)
+	assert.Equal(t, "cuAddress", cu.Address)
+	assert.Equal(t, "2.0.0", cu.Version)
+	assert.Equal(t, int64(1717431046567), cu.Timestamp)
+	assert.Contains(t, string(cu.Raw), "processCheckpointTrustedOwners")
+
+	mu, err := ao.MUInfo()
+	assert.NoError(t, err)
+	assert.Equal(t, "muAddress", mu.Address)
+	assert.Equal(t, int64(1717431046567), mu.Timestamp)
+
+	text := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte("ao messenger unit"))
+		assert.NoError(t, err)
+	}))
+	defer text.Close()
+	ao, err = New(WithMUURL(text.URL))
+	assert.NoError(t, err)
+	_, err = ao.MUInfo()
+	assert.ErrorContains(t, err, "body: ao messenger unit")
+
+	var info UnitInfo
+	err = json.Unmarshal([]byte(`{"timestamp": "now"}`), &info)
+	assert.ErrorContains(t, err, `invalid integer value "now"`)
+}
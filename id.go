@@ -0,0 +1,17 @@
+package aogo
+
+import "fmt"
+
+// validateID checks that id, named kind in errors, is a 43 character base64url transaction ID,
+// so typos and truncated IDs fail with ErrInvalidID instead of a 404 from a unit.
+func validateID(kind string, id string) error {
+	if len(id) != 43 {
+		return fmt.Errorf("%w: %s %q must be 43 base64url characters, got %d", ErrInvalidID, kind, id, len(id))
+	}
+	for _, c := range id {
+		if !(c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+			return fmt.Errorf("%w: %s %q contains %q, which is not a base64url character", ErrInvalidID, kind, id, c)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package aogo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateID(t *testing.T) {
+	assert.NoError(t, validateID("process", testProcess))
+	for _, id := range []string{"", "process", testProcess[:42], testProcess + "A", "yugMfaR+u_11GkAuZhqeChPuzoxVYuJW8RnNCIby-D8", "yugMfaR-u_11GkAuZhqeChPuzoxVYuJW8RnNCIby-D="} {
+		assert.ErrorIs(t, validateID("process", id), ErrInvalidID, id)
+	}
+}
+
+func TestInvalidID(t *testing.T) {
+	// No unit is reachable, so any request that is made fails with a different error.
+	ao := &AO{cu: newCU("http://127.0.0.1:0"), mu: newMU("http://127.0.0.1:0")}
+	s := setupSigner(t)
+
+	_, err := ao.LoadResult("process", testMessage)
+	assert.ErrorIs(t, err, ErrInvalidID)
+	assert.ErrorContains(t, err, `process "process"`)
+	_, err = ao.LoadResult(testProcess, testMessage[1:])
+	assert.ErrorIs(t, err, ErrInvalidID)
+	_, err = ao.SendMessage("process", "data", nil, "", s)
+	assert.ErrorIs(t, err, ErrInvalidID)
+	_, err = ao.SpawnProcess("module", nil, nil, s)
+	assert.ErrorIs(t, err, ErrInvalidID)
+	assert.ErrorContains(t, err, `module "module"`)
+}
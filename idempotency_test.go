@@ -0,0 +1,109 @@
+package aogo
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/liteseed/goar/transaction/data_item"
+	"github.com/stretchr/testify/assert"
+)
+
+// idempotencyServer plays the MU, which accepts the first message but answers it with a 503, and the SU,
+// which answers lookups with suStatus for messages the MU accepted.
+type idempotencyServer struct {
+	mu       sync.Mutex
+	posts    int
+	lookups  int
+	accepted map[string]bool
+}
+
+func newIdempotencyServer(t *testing.T, suStatus int) (*idempotencyServer, *httptest.Server) {
+	s := &idempotencyServer{accepted: map[string]bool{}}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if r.Method == http.MethodGet {
+			s.lookups++
+			assert.Equal(t, testProcess, r.URL.Query().Get("process-id"))
+			id := strings.TrimPrefix(r.URL.Path, "/")
+			if !s.accepted[id] {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(suStatus)
+			return
+		}
+		s.posts++
+		b, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		item, err := data_item.Decode(b)
+		assert.NoError(t, err)
+		if s.posts == 1 {
+			s.accepted[item.ID] = true
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, err = w.Write([]byte(`{"id": "` + item.ID + `"}`))
+		assert.NoError(t, err)
+	}))
+	return s, srv
+}
+
+func TestWithIdempotentSends(t *testing.T) {
+	retry := WithMURetry(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond})
+
+	t.Run("AlreadyScheduled", func(t *testing.T) {
+		s, srv := newIdempotencyServer(t, http.StatusOK)
+		defer srv.Close()
+
+		ao, err := New(WithMUURL(srv.URL), WithSUURL(srv.URL), retry, WithIdempotentSends())
+		assert.NoError(t, err)
+		id, err := ao.SendMessage(testProcess, "data", nil, "", setupSigner(t))
+		assert.NoError(t, err)
+		assert.True(t, s.accepted[id])
+		assert.Equal(t, 1, s.posts)
+		assert.Equal(t, 1, s.lookups)
+	})
+
+	t.Run("NotScheduled", func(t *testing.T) {
+		// The SU has not scheduled the message the MU accepted.
+		s, srv := newIdempotencyServer(t, http.StatusNotFound)
+		defer srv.Close()
+
+		ao, err := New(WithMUURL(srv.URL), WithSUURL(srv.URL), retry, WithIdempotentSends())
+		assert.NoError(t, err)
+		id, err := ao.SendMessage(testProcess, "data", nil, "", setupSigner(t))
+		assert.NoError(t, err)
+		assert.NotEmpty(t, id)
+		assert.Equal(t, 2, s.posts)
+		assert.Equal(t, 1, s.lookups)
+	})
+
+	t.Run("LookupFails", func(t *testing.T) {
+		s, srv := newIdempotencyServer(t, http.StatusInternalServerError)
+		defer srv.Close()
+
+		ao, err := New(WithMUURL(srv.URL), WithSUURL(srv.URL), retry, WithIdempotentSends())
+		assert.NoError(t, err)
+		_, err = ao.SendMessage(testProcess, "data", nil, "", setupSigner(t))
+		assert.NoError(t, err)
+		assert.Equal(t, 2, s.posts)
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		s, srv := newIdempotencyServer(t, http.StatusOK)
+		defer srv.Close()
+
+		ao, err := New(WithMUURL(srv.URL), WithSUURL(srv.URL), retry)
+		assert.NoError(t, err)
+		_, err = ao.SendMessage(testProcess, "data", nil, "", setupSigner(t))
+		assert.NoError(t, err)
+		assert.Equal(t, 2, s.posts)
+		assert.Zero(t, s.lookups)
+	})
+}
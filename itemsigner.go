@@ -0,0 +1,148 @@
+package aogo
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/liteseed/goar/crypto"
+	"github.com/liteseed/goar/signer"
+	"github.com/liteseed/goar/tag"
+	"github.com/liteseed/goar/transaction/data_item"
+)
+
+// Signer signs ANS-104 data items with any of the signature types in data_item.SignatureConfig, so keys other
+// than Arweave RSA keys, such as Ethereum secp256k1 keys, can spawn processes and send messages. Set one with
+// WithDataItemSigner; ArweaveSigner adapts a goar RSA signer.
+type Signer interface {
+	// SignatureType is the ANS-104 signature type written to the data item, for example data_item.Arweave or
+	// data_item.Ethereum.
+	SignatureType() int
+	// Owner is the raw public key, of the length the signature type requires: 65 uncompressed bytes for
+	// Ethereum, the 512 byte modulus for Arweave.
+	Owner() []byte
+	// Sign signs message, the deep hash of the data item, and returns a signature of the length the signature
+	// type requires. Ethereum signers apply the EIP-191 personal message prefix themselves.
+	Sign(message []byte) ([]byte, error)
+}
+
+type arweaveSigner struct {
+	s *signer.Signer
+}
+
+// ArweaveSigner adapts s, an Arweave RSA signer, to Signer.
+func ArweaveSigner(s *signer.Signer) Signer {
+	return arweaveSigner{s: s}
+}
+
+func (a arweaveSigner) SignatureType() int { return data_item.Arweave }
+
+func (a arweaveSigner) Owner() []byte { return a.s.PublicKey.N.Bytes() }
+
+func (a arweaveSigner) Sign(message []byte) ([]byte, error) {
+	return crypto.Sign(message, a.s.PrivateKey)
+}
+
+// itemSigner adapts s to Signer, keeping a nil s nil.
+func itemSigner(s *signer.Signer) Signer {
+	if s == nil {
+		return nil
+	}
+	return ArweaveSigner(s)
+}
+
+// itemSignerOr returns s as a Signer or, when s is nil, the signer set by WithDataItemSigner, falling back to
+// the one set by WithSigner.
+func (ao *AO) itemSignerOr(s *signer.Signer) Signer {
+	if s == nil && ao.itemSigner != nil {
+		return ao.itemSigner
+	}
+	return itemSigner(ao.signerOr(s))
+}
+
+// signDataItem checks the tags of item, then signs it with s and encodes it, setting its signature type, owner,
+// signature, ID and raw bytes.
+func signDataItem(item *data_item.DataItem, s Signer) error {
+	if err := validateTags(*item.Tags); err != nil {
+		return err
+	}
+	signatureType := s.SignatureType()
+	config, ok := data_item.SignatureConfig[signatureType]
+	if !ok {
+		return fmt.Errorf("%w: unsupported signature type %d", ErrInvalidSigner, signatureType)
+	}
+	owner := s.Owner()
+	if len(owner) != config.PublicKeyLength {
+		return fmt.Errorf("%w: %s owner is %d bytes, want %d", ErrInvalidSigner, config.Name, len(owner), config.PublicKeyLength)
+	}
+	target, err := crypto.Base64URLDecode(item.Target)
+	if err != nil {
+		return err
+	}
+	tags, err := tag.Serialize(item.Tags)
+	if err != nil {
+		return err
+	}
+	data, err := crypto.Base64URLDecode(item.Data)
+	if err != nil {
+		return err
+	}
+
+	hash := dataItemHash(signatureType, owner, target, []byte(item.Anchor), tags, data)
+	signature, err := s.Sign(hash[:])
+	if err != nil {
+		return err
+	}
+	if len(signature) != config.SignatureLength {
+		return fmt.Errorf("%w: %s signature is %d bytes, want %d", ErrInvalidSigner, config.Name, len(signature), config.SignatureLength)
+	}
+
+	raw := binary.LittleEndian.AppendUint16(nil, uint16(signatureType))
+	raw = append(raw, signature...)
+	raw = append(raw, owner...)
+	raw = appendOptional(raw, target)
+	raw = appendOptional(raw, []byte(item.Anchor))
+	raw = binary.LittleEndian.AppendUint64(raw, uint64(len(*item.Tags)))
+	raw = binary.LittleEndian.AppendUint64(raw, uint64(len(tags)))
+	raw = append(raw, tags...)
+	raw = append(raw, data...)
+
+	item.SignatureType = signatureType
+	item.Owner = crypto.Base64URLEncode(owner)
+	item.Signature = crypto.Base64URLEncode(signature)
+	item.ID = crypto.Base64URLEncode(crypto.SHA256(signature))
+	item.Raw = raw
+	return nil
+}
+
+// dataItemHash returns the ANS-104 deep hash of the fields of a data item, which is what its owner signs.
+func dataItemHash(signatureType int, owner, target, anchor, tags, data []byte) [48]byte {
+	return crypto.DeepHash([][]byte{
+		[]byte("dataitem"),
+		[]byte("1"),
+		[]byte(strconv.Itoa(signatureType)),
+		owner,
+		target,
+		anchor,
+		tags,
+		data,
+	})
+}
+
+// appendOptional appends the presence byte of an optional data item field followed by the field itself.
+func appendOptional(raw []byte, field []byte) []byte {
+	if len(field) == 0 {
+		return append(raw, 0)
+	}
+	return append(append(raw, 1), field...)
+}
+
+// failedWith wraps err, the AOError of a failed submission signed by s, naming the signature type when the MU
+// rejected the signature.
+func failedWith(op string, err *AOError, s Signer) error {
+	if errors.Is(err, ErrSignatureRejected) {
+		return fmt.Errorf("%s: %s signature rejected: %w", op, data_item.SignatureConfig[s.SignatureType()].Name, err)
+	}
+	return fmt.Errorf("%s: %w", op, err)
+}
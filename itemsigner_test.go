@@ -0,0 +1,153 @@
+package aogo
+
+import (
+	"crypto/ed25519"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/liteseed/goar/crypto"
+	"github.com/liteseed/goar/tag"
+	"github.com/liteseed/goar/transaction/data_item"
+	"github.com/stretchr/testify/assert"
+)
+
+// ed25519Signer signs data items with an ED25519 key, standing in for non RSA signers such as Ethereum ones.
+type ed25519Signer struct {
+	key ed25519.PrivateKey
+}
+
+func (e ed25519Signer) SignatureType() int { return data_item.ED25519 }
+
+func (e ed25519Signer) Owner() []byte { return e.key.Public().(ed25519.PublicKey) }
+
+func (e ed25519Signer) Sign(message []byte) ([]byte, error) { return ed25519.Sign(e.key, message), nil }
+
+func newED25519Signer(t *testing.T) ed25519Signer {
+	_, key, err := ed25519.GenerateKey(nil)
+	assert.NoError(t, err)
+	return ed25519Signer{key: key}
+}
+
+func TestSignDataItem(t *testing.T) {
+	t.Run("Arweave", func(t *testing.T) {
+		item := data_item.New([]byte("data"), testProcess, "00000000000000000000000000000001", &[]tag.Tag{{Name: "Action", Value: "Eval"}})
+		assert.NoError(t, signDataItem(item, ArweaveSigner(setupSigner(t))))
+
+		decoded, err := data_item.Decode(item.Raw)
+		assert.NoError(t, err)
+		assert.NoError(t, decoded.Verify())
+		assert.Equal(t, data_item.Arweave, decoded.SignatureType)
+		assert.Equal(t, item.ID, decoded.ID)
+		assert.Equal(t, testProcess, decoded.Target)
+	})
+
+	t.Run("ED25519", func(t *testing.T) {
+		s := newED25519Signer(t)
+		item := data_item.New([]byte("data"), testProcess, "", &[]tag.Tag{{Name: "Action", Value: "Eval"}})
+		assert.NoError(t, signDataItem(item, s))
+
+		decoded, err := data_item.Decode(item.Raw)
+		assert.NoError(t, err)
+		assert.Equal(t, data_item.ED25519, decoded.SignatureType)
+		assert.Equal(t, crypto.Base64URLEncode(s.Owner()), decoded.Owner)
+		assert.Equal(t, *item.Tags, *decoded.Tags)
+		id, err := DataItemID(item.Raw)
+		assert.NoError(t, err)
+		assert.Equal(t, item.ID, id)
+
+		tags, err := tag.Serialize(decoded.Tags)
+		assert.NoError(t, err)
+		target, err := crypto.Base64URLDecode(decoded.Target)
+		assert.NoError(t, err)
+		hash := crypto.DeepHash([][]byte{[]byte("dataitem"), []byte("1"), []byte(strconv.Itoa(data_item.ED25519)),
+			s.Owner(), target, nil, tags, []byte("data")})
+		signature, err := crypto.Base64URLDecode(decoded.Signature)
+		assert.NoError(t, err)
+		assert.True(t, ed25519.Verify(s.Owner(), hash[:], signature))
+	})
+
+	t.Run("WrongOwnerLength", func(t *testing.T) {
+		s := newED25519Signer(t)
+		item := data_item.New(nil, "", "", nil)
+		err := signDataItem(item, badOwner{s})
+		assert.ErrorIs(t, err, ErrInvalidSigner)
+		assert.ErrorContains(t, err, "want 32")
+	})
+}
+
+type badOwner struct {
+	ed25519Signer
+}
+
+func (b badOwner) Owner() []byte { return []byte("short") }
+
+func TestWithDataItemSigner(t *testing.T) {
+	var signatureType int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		item, err := data_item.Decode(b)
+		assert.NoError(t, err)
+		signatureType = item.SignatureType
+		_, err = w.Write([]byte(`{"id": "` + item.ID + `"}`))
+		assert.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	ao, err := New(WithMUURL(srv.URL), WithSigner(setupSigner(t)), WithDataItemSigner(newED25519Signer(t)), WithVerifyIDs())
+	assert.NoError(t, err)
+
+	_, err = ao.SendMessage(testProcess, "data", nil, "", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, data_item.ED25519, signatureType)
+
+	_, err = ao.SpawnProcess(testModule, nil, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, data_item.ED25519, signatureType)
+
+	// An explicit signer still wins.
+	_, err = ao.SendMessage(testProcess, "data", nil, "", setupSigner(t))
+	assert.NoError(t, err)
+	assert.Equal(t, data_item.Arweave, signatureType)
+}
+
+func TestSignerType(t *testing.T) {
+	ao, err := New()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, ao.SignerType())
+
+	ao, err = New(WithSigner(setupSigner(t)))
+	assert.NoError(t, err)
+	assert.Equal(t, data_item.Arweave, ao.SignerType())
+	assert.Len(t, ArweaveSigner(setupSigner(t)).Owner(), data_item.SignatureConfig[data_item.Arweave].PublicKeyLength)
+
+	ao, err = New(WithSigner(setupSigner(t)), WithDataItemSigner(newED25519Signer(t)))
+	assert.NoError(t, err)
+	assert.Equal(t, data_item.ED25519, ao.SignerType())
+}
+
+func TestSignatureRejected(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte(`{"error": "Unsupported signature type"}`))
+		assert.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	ao, err := New(WithMUURL(srv.URL), WithDataItemSigner(newED25519Signer(t)))
+	assert.NoError(t, err)
+
+	_, err = ao.SendMessage(testProcess, "data", nil, "", nil)
+	assert.ErrorIs(t, err, ErrSignatureRejected)
+	assert.ErrorContains(t, err, "message failed: ed25519 signature rejected")
+
+	_, err = ao.SpawnProcess(testModule, nil, nil, nil)
+	assert.ErrorIs(t, err, ErrSignatureRejected)
+
+	// Other client errors are not blamed on the signature.
+	aoErr := &AOError{Unit: UnitMU, StatusCode: http.StatusBadRequest, Body: "bad tags"}
+	assert.NotErrorIs(t, aoErr, ErrSignatureRejected)
+}
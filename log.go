@@ -0,0 +1,61 @@
+package aogo
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// sendRequest performs req with client and logs its method, URL, status and latency to logger at debug level.
+// Request and response bodies are never logged. A nil logger disables logging.
+// Headers in header are added to req unless it already sets them, and observer, if any, receives the outcome,
+// as does the CallStats of the request context.
+// Responses are requested with gzip and transparently decompressed.
+func sendRequest(client *http.Client, logger *slog.Logger, observer Observer, unit Unit, header http.Header, req *http.Request) (*http.Response, error) {
+	for name, values := range header {
+		if _, ok := req.Header[name]; !ok {
+			req.Header[name] = append([]string(nil), values...)
+		}
+	}
+	if req.Header.Get("Accept-Encoding") == "" {
+		req.Header.Set("Accept-Encoding", "gzip")
+	}
+	if observer != nil {
+		observeRetry(observer, unit, req)
+	}
+	start := time.Now()
+	resp, err := client.Do(req)
+	recordCall(req, unit, start, time.Now())
+	if observer != nil {
+		status := 0
+		if err == nil {
+			status = resp.StatusCode
+		}
+		observer.ObserveRequest(req.Method, string(unit), status, time.Since(start))
+	}
+	if span, ok := spanFromContext(req.Context()); ok && err == nil {
+		span.SetStatusCode(resp.StatusCode)
+	}
+	if err == nil {
+		if err = decompressResponse(resp); err != nil {
+			resp = nil
+		}
+	}
+	if logger == nil {
+		return resp, err
+	}
+
+	attrs := []slog.Attr{
+		slog.String("unit", string(unit)),
+		slog.String("method", req.Method),
+		slog.String("url", req.URL.Redacted()),
+		slog.Duration("latency", time.Since(start)),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	} else {
+		attrs = append(attrs, slog.Int("status", resp.StatusCode))
+	}
+	logger.LogAttrs(req.Context(), slog.LevelDebug, "ao request", attrs...)
+	return resp, err
+}
@@ -0,0 +1,59 @@
+package aogo
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/liteseed/goar/signer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithLogger(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			_, err := w.Write([]byte(`{"id": "mockMessageID"}`))
+			assert.NoError(t, err)
+			return
+		}
+		_, err := w.Write([]byte(`{"Messages": [], "Spawns": [], "Outputs": [], "Error": "", "GasUsed": 0}`))
+		assert.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	ao, err := New(WithCUURL(srv.URL), WithMUURL(srv.URL), WithLogger(logger))
+	assert.NoError(t, err)
+
+	_, err = ao.LoadResult(testProcess, testMessage)
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), "unit=cu")
+	assert.Contains(t, buf.String(), "method=GET")
+	assert.Contains(t, buf.String(), "/result/"+testMessage+"?process-id="+testProcess)
+	assert.Contains(t, buf.String(), "status=200")
+	assert.Contains(t, buf.String(), "latency=")
+
+	s, err := signer.FromPath("./keys/wallet.json")
+	assert.NoError(t, err)
+	buf.Reset()
+	_, err = ao.SendMessage(testProcess, "secret payload", nil, "", s)
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), "unit=mu")
+	assert.NotContains(t, buf.String(), "secret payload")
+	assert.NotContains(t, buf.String(), s.Owner())
+}
+
+func TestWithoutLogger(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"Messages": [], "Spawns": [], "Outputs": [], "Error": "", "GasUsed": 0}`))
+		assert.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	ao := &AO{cu: NewCUMock(srv.URL)}
+	_, err := ao.LoadResult(testProcess, testMessage)
+	assert.NoError(t, err)
+}
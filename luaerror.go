@@ -0,0 +1,85 @@
+package aogo
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// LuaError is the Error of a result broken down, when it follows the standard Lua format, into the error message,
+// where it was raised and the frames of its stack traceback.
+type LuaError struct {
+	// Message is the error message without its location. It is the whole raw error when that could not be parsed.
+	Message string
+	// Source is the chunk the error was raised in, such as [string "aos"] or /process.lua, or empty if unknown.
+	Source string
+	// Line is the line of Source the error was raised on, or 0 if unknown.
+	Line int
+	// Frames are the frames of the stack traceback, innermost first.
+	Frames []LuaFrame
+	// Raw is the error as the process reported it.
+	Raw string
+}
+
+// LuaFrame is one frame of a Lua stack traceback.
+type LuaFrame struct {
+	// Source is the chunk of the frame, such as [string "aos"] or [C] for a Go or C function.
+	Source string
+	// Line is the current line of the frame, or 0 when Lua does not know it.
+	Line int
+	// Function describes the function, such as function 'handle' or main chunk.
+	Function string
+}
+
+// Location returns Source:Line, or Source alone when the line is unknown.
+func (e *LuaError) Location() string {
+	if e.Line == 0 {
+		return e.Source
+	}
+	return e.Source + ":" + strconv.Itoa(e.Line)
+}
+
+func (e *LuaError) Error() string {
+	return e.Raw
+}
+
+var (
+	// luaErrorPattern matches "source:line: message"; sources may contain colons only inside [string "..."].
+	luaErrorPattern = regexp.MustCompile(`^(\[string ".*?"\]|[^:\s]+):(\d+): (?s)(.*)$`)
+	// luaFramePattern matches "source:line: in function" and "[C]: in function".
+	luaFramePattern = regexp.MustCompile(`^(\[string ".*?"\]|\[C\]|[^:\s]+):(?:(\d+):)? in (.*)$`)
+)
+
+// ParsedError returns the Error of r broken down into a LuaError, or nil if r reports no error.
+func (r *Response) ParsedError() *LuaError {
+	if r.Error == "" {
+		return nil
+	}
+	return parseLuaError(r.Error)
+}
+
+// ParsedError returns the Message of e broken down into a LuaError.
+func (e *ProcessError) ParsedError() *LuaError {
+	return parseLuaError(e.Message)
+}
+
+func parseLuaError(raw string) *LuaError {
+	e := &LuaError{Message: raw, Raw: raw}
+	message, traceback, _ := strings.Cut(raw, "stack traceback:")
+	message = strings.TrimSpace(message)
+	if m := luaErrorPattern.FindStringSubmatch(message); m != nil {
+		e.Source = m[1]
+		e.Line, _ = strconv.Atoi(m[2])
+		e.Message = m[3]
+	}
+	for _, line := range strings.Split(traceback, "\n") {
+		m := luaFramePattern.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+		frame := LuaFrame{Source: m[1], Function: m[3]}
+		frame.Line, _ = strconv.Atoi(m[2])
+		e.Frames = append(e.Frames, frame)
+	}
+	return e
+}
@@ -0,0 +1,45 @@
+package aogo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsedError(t *testing.T) {
+	t.Run("Traceback", func(t *testing.T) {
+		raw := "[string \"aos\"]:42: attempt to index a nil value (field 'x')\n" +
+			"stack traceback:\n" +
+			"\t[string \"aos\"]:42: in function 'handle'\n" +
+			"\t[C]: in function 'pcall'\n" +
+			"\t/process.lua:310: in function 'process'\n" +
+			"\t(...tail calls...)"
+		e := (&Response{Error: raw}).ParsedError()
+		assert.Equal(t, "attempt to index a nil value (field 'x')", e.Message)
+		assert.Equal(t, `[string "aos"]`, e.Source)
+		assert.Equal(t, 42, e.Line)
+		assert.Equal(t, `[string "aos"]:42`, e.Location())
+		assert.Equal(t, []LuaFrame{
+			{Source: `[string "aos"]`, Line: 42, Function: "function 'handle'"},
+			{Source: "[C]", Function: "function 'pcall'"},
+			{Source: "/process.lua", Line: 310, Function: "function 'process'"},
+		}, e.Frames)
+		assert.Equal(t, raw, e.Raw)
+	})
+
+	t.Run("NoTraceback", func(t *testing.T) {
+		e := (&ProcessError{Message: "/process.lua:7: insufficient balance"}).ParsedError()
+		assert.Equal(t, "insufficient balance", e.Message)
+		assert.Equal(t, "/process.lua:7", e.Location())
+		assert.Empty(t, e.Frames)
+	})
+
+	t.Run("Unparsed", func(t *testing.T) {
+		e := (&Response{Error: "out of memory"}).ParsedError()
+		assert.Equal(t, "out of memory", e.Message)
+		assert.Empty(t, e.Location())
+		assert.Equal(t, "out of memory", e.Error())
+	})
+
+	assert.Nil(t, (&Response{}).ParsedError())
+}
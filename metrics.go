@@ -0,0 +1,44 @@
+package aogo
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Observer receives a measurement after every HTTP request made to a unit, for metrics such as call volume,
+// error rate and latency. method is the HTTP method, unit one of the Unit values, and status is 0 when the
+// request failed without a response. Retried requests are observed once per attempt.
+// Observers are called from several goroutines at once.
+type Observer interface {
+	ObserveRequest(method string, unit string, status int, dur time.Duration)
+}
+
+// RetryObserver is implemented by an Observer that also counts retries. ObserveRetry is called before every
+// attempt after the first, with attempt starting at 2, so flaky units stand out.
+type RetryObserver interface {
+	ObserveRetry(method string, unit string, attempt int)
+}
+
+// FlushObserver is implemented by an Observer that buffers measurements. Close flushes it.
+type FlushObserver interface {
+	Flush() error
+}
+
+type attemptKey struct{}
+
+// withAttempt records in req which attempt of the retry loop it is.
+func withAttempt(req *http.Request, attempt int) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), attemptKey{}, attempt))
+}
+
+// observeRetry reports req to observer if it is a retry and observer counts retries.
+func observeRetry(observer Observer, unit Unit, req *http.Request) {
+	ro, ok := observer.(RetryObserver)
+	if !ok {
+		return
+	}
+	if attempt, _ := req.Context().Value(attemptKey{}).(int); attempt > 1 {
+		ro.ObserveRetry(req.Method, string(unit), attempt)
+	}
+}
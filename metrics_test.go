@@ -0,0 +1,107 @@
+package aogo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type observation struct {
+	method string
+	unit   string
+	status int
+}
+
+type recordingObserver struct {
+	mu       sync.Mutex
+	requests []observation
+	retries  []int
+}
+
+func (o *recordingObserver) ObserveRequest(method string, unit string, status int, dur time.Duration) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.requests = append(o.requests, observation{method, unit, status})
+}
+
+func (o *recordingObserver) ObserveRetry(method string, unit string, attempt int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.retries = append(o.retries, attempt)
+}
+
+type requestObserver struct{ count int }
+
+func (o *requestObserver) ObserveRequest(method string, unit string, status int, dur time.Duration) {
+	o.count++
+}
+
+func TestWithObserver(t *testing.T) {
+	t.Run("Request", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, err := w.Write([]byte(`{"Messages": [{"Data": "1"}], "Spawns": [], "Outputs": [], "Error": "", "GasUsed": 0}`))
+			assert.NoError(t, err)
+		}))
+		defer srv.Close()
+
+		o := &recordingObserver{}
+		ao, err := New(WithCUURL(srv.URL), WithObserver(o))
+		assert.NoError(t, err)
+		_, err = ao.LoadResult(testProcess, testMessage)
+		assert.NoError(t, err)
+		assert.Equal(t, []observation{{"GET", "cu", 200}}, o.requests)
+		assert.Empty(t, o.retries)
+	})
+
+	t.Run("Retries", func(t *testing.T) {
+		calls := 0
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if calls < 3 {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			_, err := w.Write([]byte(`{"Messages": [{"Data": "1"}], "Spawns": [], "Outputs": [], "Error": "", "GasUsed": 0}`))
+			assert.NoError(t, err)
+		}))
+		defer srv.Close()
+
+		o := &recordingObserver{}
+		ao, err := New(WithCUURL(srv.URL), WithCURetry(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}), WithObserver(o))
+		assert.NoError(t, err)
+		_, err = ao.LoadResult(testProcess, testMessage)
+		assert.NoError(t, err)
+		assert.Equal(t, []observation{{"GET", "cu", 502}, {"GET", "cu", 502}, {"GET", "cu", 200}}, o.requests)
+		assert.Equal(t, []int{2, 3}, o.retries)
+	})
+
+	t.Run("TransportError", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		srv.Close()
+
+		o := &recordingObserver{}
+		ao, err := New(WithCUURL(srv.URL), WithCURetry(RetryPolicy{MaxAttempts: 1}), WithObserver(o))
+		assert.NoError(t, err)
+		_, err = ao.LoadResult(testProcess, testMessage)
+		assert.Error(t, err)
+		assert.Equal(t, []observation{{"GET", "cu", 0}}, o.requests)
+	})
+
+	t.Run("WithoutRetryObserver", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer srv.Close()
+
+		o := &requestObserver{}
+		ao, err := New(WithCUURL(srv.URL), WithCURetry(RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}), WithObserver(o))
+		assert.NoError(t, err)
+		_, err = ao.LoadResult(testProcess, testMessage)
+		assert.Error(t, err)
+		assert.Equal(t, 2, o.count)
+	})
+}
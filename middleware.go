@@ -0,0 +1,39 @@
+package aogo
+
+import "net/http"
+
+// Middleware wraps the transport of the HTTP client used for unit requests, for cross-cutting concerns such
+// as auth headers, request IDs or metrics. It sees every attempt, including retries and failovers.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc adapts a function to an http.RoundTripper.
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// applyMiddleware gives every unit a copy of its client whose transport is wrapped by ao.middleware,
+// the first middleware being the outermost. Shared clients, including http.DefaultClient, are not modified.
+func (ao *AO) applyMiddleware() {
+	if len(ao.middleware) == 0 {
+		return
+	}
+	ao.cu.client = wrapClient(ao.cu.client, ao.middleware)
+	ao.mu.client = wrapClient(ao.mu.client, ao.middleware)
+	ao.su.client = wrapClient(ao.su.client, ao.middleware)
+	ao.gateway.client = wrapClient(ao.gateway.client, ao.middleware)
+}
+
+func wrapClient(client *http.Client, middleware []Middleware) *http.Client {
+	c := *client
+	rt := c.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	for i := len(middleware) - 1; i >= 0; i-- {
+		rt = middleware[i](rt)
+	}
+	c.Transport = rt
+	return &c
+}
@@ -0,0 +1,62 @@
+package aogo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithMiddleware(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		assert.Equal(t, "outer,inner", r.Header.Get("X-Order"))
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, err := w.Write([]byte(`{"Messages": []}`))
+		assert.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	var attempts atomic.Int32
+	header := func(name string, value string) Middleware {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				req = req.Clone(req.Context())
+				v := value
+				if prev := req.Header.Get(name); prev != "" {
+					v = prev + "," + v
+				}
+				req.Header.Set(name, v)
+				return next.RoundTrip(req)
+			})
+		}
+	}
+	count := func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			attempts.Add(1)
+			return next.RoundTrip(req)
+		})
+	}
+
+	client := &http.Client{}
+	ao, err := New(
+		WithMiddleware(count, header("Authorization", "Bearer token")),
+		WithMiddleware(header("X-Order", "outer"), header("X-Order", "inner")),
+		WithCUURL(srv.URL),
+		WithHTTPClient(client),
+		WithCURetry(RetryPolicy{MaxAttempts: 2}),
+	)
+	assert.NoError(t, err)
+
+	_, err = ao.LoadResult(testProcess, testMessage)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), attempts.Load())
+	assert.Nil(t, client.Transport)
+	assert.Nil(t, http.DefaultClient.Transport)
+}
@@ -2,31 +2,68 @@ package aogo
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
+	"log/slog"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/liteseed/goar/signer"
 	"github.com/liteseed/goar/tag"
 	"github.com/liteseed/goar/transaction/data_item"
+	"golang.org/x/time/rate"
 )
 
-type IMU interface {
-	SendMessage(process string, data string, tags []tag.Tag, s *signer.Signer) (string, error)
-	SpawnProcess(data string, tags []tag.Tag, s *signer.Signer) (string, error)
-
-	Monitor()
+// MessengerUnit submits signed messages and spawns. MU is the HTTP implementation; tests can supply their own
+// through NewWithUnits.
+type MessengerUnit interface {
+	SendMessage(process string, data string, tags *[]tag.Tag, anchor string, s *signer.Signer) (string, error)
+	SpawnProcess(module string, data []byte, tags []tag.Tag, s *signer.Signer) (string, error)
 }
+
+// IMU is the former name of MessengerUnit.
+//
+// Deprecated: use MessengerUnit.
+type IMU = MessengerUnit
+
 type MU struct {
-	client *http.Client
-	url    string
+	client       *http.Client
+	url          string
+	timeout      time.Duration
+	retry        RetryPolicy
+	logger       *slog.Logger
+	observer     Observer
+	breaker      *circuitBreaker
+	maxErrorBody int
+	// maxResponseBytes caps buffered response bodies, see WithMaxResponseBytes.
+	maxResponseBytes int64
+	// header is added to every request.
+	header  http.Header
+	limiter *rate.Limiter
+	anchors *anchors
+	// references numbers messages without a Reference tag, see WithAutoReference.
+	references *references
+	// verifyIDs makes sends fail with ErrIDMismatch when the MU answers with an unexpected ID.
+	verifyIDs bool
+	// scheduled, if set, reports whether the SU already has a message. It is asked before a send is retried.
+	scheduled func(ctx context.Context, process string, id string) (bool, error)
+	// variant is the Variant tag of signed data items; empty means DefaultVariant.
+	variant string
+	// noSDKTags leaves out the SDK and SDK-Version tags, see WithoutSDKTags.
+	noSDKTags bool
+	// maxItemSize, if positive, is the largest data item posted, see WithMaxDataItemSize.
+	maxItemSize int64
 }
 
 func newMU(url string) MU {
 	return MU{
 		client: http.DefaultClient,
 		url:    url,
+		retry:  DefaultRetryPolicy,
 	}
 }
 
@@ -40,96 +77,379 @@ type SpawnProcessResponse struct {
 }
 
 func (mu *MU) SendMessage(process string, data string, tags *[]tag.Tag, anchor string, s *signer.Signer) (string, error) {
-	if tags == nil {
-		tags = &[]tag.Tag{}
-	}
-	*tags = append(*tags, tag.Tag{Name: "Data-Protocol", Value: "ao"},
-		tag.Tag{Name: "Variant", Value: "ao.TN.1"},
-		tag.Tag{Name: "Type", Value: "Message"},
-		tag.Tag{Name: "SDK", Value: SDK})
+	return mu.SendMessageBytes(process, []byte(data), tags, anchor, s)
+}
 
-	dataItem := data_item.New([]byte(data), process, anchor, tags)
-	err := dataItem.Sign(s)
+// SendMessageBytes is like SendMessage but signs data as raw bytes, preserving binary payloads exactly.
+func (mu *MU) SendMessageBytes(process string, data []byte, tags *[]tag.Tag, anchor string, s *signer.Signer) (string, error) {
+	res, err := mu.sendMessage(context.Background(), process, data, tags, anchor, itemSigner(s))
 	if err != nil {
 		return "", err
 	}
+	return res.ID, nil
+}
 
-	req, err := http.NewRequest("POST", mu.url, bytes.NewBuffer(dataItem.Raw))
+// MessageResult describes a message sent through the MU.
+type MessageResult struct {
+	// ID is the ID of the message as returned by the MU.
+	ID string
+	// DataItemID is the ID of the signed data item computed locally before it was sent; see DataItemID. It is
+	// empty when the message was sent through a MessengerUnit set by NewWithUnits.
+	DataItemID string
+	// Reference is the Reference tag of the message, which replies to it carry as X-Reference. It is empty when
+	// the message has none.
+	Reference string
+}
+
+// SendMessageResult is like SendMessage but also returns the ID of the signed data item, which should match the
+// ID the MU answered with.
+func (mu *MU) SendMessageResult(process string, data string, tags *[]tag.Tag, anchor string, s *signer.Signer) (*MessageResult, error) {
+	return mu.sendMessage(context.Background(), process, []byte(data), tags, anchor, itemSigner(s))
+}
+
+func (mu *MU) sendMessage(ctx context.Context, process string, data []byte, tags *[]tag.Tag, anchor string, s Signer) (*MessageResult, error) {
+	dataItem, err := mu.messageItem(process, data, tags, anchor, s)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	req.Header.Set("content-type", "application/octet-stream")
-	req.Header.Set("accept", "application/json")
 
-	resp, err := mu.client.Do(req)
+	resp, b, err := mu.send(ctx, "POST", "", dataItem.Raw, mu.alreadyScheduled(process, dataItem.ID))
+	reference, _ := FindTag(*dataItem.Tags, "Reference")
+	if errors.Is(err, errScheduled) {
+		return &MessageResult{ID: dataItem.ID, DataItemID: dataItem.ID, Reference: reference}, nil
+	}
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	defer resp.Body.Close()
 
 	if resp.StatusCode >= http.StatusBadRequest {
-		return "", fmt.Errorf("message failed: %s", resp.Status)
+		return nil, failedWith("message failed", newAOError(UnitMU, resp, b, mu.maxErrorBody), s)
+	}
+
+	var res SendMessageResponse
+	err = json.Unmarshal(b, &res)
+	if err != nil {
+		return nil, unmarshalError("response", err, b, mu.maxErrorBody)
+	}
+
+	if err := mu.verifyID(ctx, res.ID, dataItem.ID); err != nil {
+		return nil, err
+	}
+	return &MessageResult{ID: res.ID, DataItemID: dataItem.ID, Reference: reference}, nil
+}
+
+// messageItem signs the data item of a message to process, with an automatic anchor if anchor is empty and
+// WithAutoAnchor is set. The protocol and SDK tags tags does not already set, and a Reference tag if it has none
+// and WithAutoReference is set, are added to a copy of it.
+func (mu *MU) messageItem(process string, data []byte, tags *[]tag.Tag, anchor string, s Signer) (*data_item.DataItem, error) {
+	if s == nil {
+		return nil, fmt.Errorf("%w: signer is required", ErrInvalidSigner)
+	}
+	if err := validateID("process", process); err != nil {
+		return nil, err
+	}
+	if anchor == "" {
+		a, err := mu.anchors.next(process)
+		if err != nil {
+			return nil, err
+		}
+		anchor = a
+	}
+	// The tags are copied so callers sharing a tag list between goroutines do not race on it.
+	var newTags []tag.Tag
+	if tags != nil {
+		newTags = append(newTags, *tags...)
 	}
+	newTags = append(newTags, missingTags(newTags, append(protocolTags("Message", mu.variant), mu.sdkTags()...)...)...)
+	newTags = mu.withReference(process, newTags)
 
-	b, err := io.ReadAll(resp.Body)
+	dataItem := data_item.New(data, process, anchor, &newTags)
+	if err := signDataItem(dataItem, s); err != nil {
+		return nil, err
+	}
+	return dataItem, nil
+}
+
+// withReference appends to tags, unless it already has one, the next Reference tag of process if
+// WithAutoReference is set.
+func (mu *MU) withReference(process string, tags []tag.Tag) []tag.Tag {
+	if _, ok := FindTag(tags, "Reference"); ok || mu.references == nil {
+		return tags
+	}
+	return append(tags, tag.Tag{Name: "Reference", Value: mu.references.take(process)})
+}
+
+// verifyID checks, if enabled, that the MU answered with the ID of the data item it was sent, logging both IDs
+// at error level when it did not.
+func (mu *MU) verifyID(ctx context.Context, returned string, signed string) error {
+	if !mu.verifyIDs || returned == signed {
+		return nil
+	}
+	if mu.logger != nil {
+		mu.logger.LogAttrs(ctx, slog.LevelError, "ao id mismatch", slog.String("unit", string(UnitMU)),
+			slog.String("url", mu.url), slog.String("returned", returned), slog.String("signed", signed))
+	}
+	return fmt.Errorf("%w: mu returned %s for data item %s", ErrIDMismatch, returned, signed)
+}
+
+func (mu *MU) SpawnProcess(module string, data []byte, tags []tag.Tag, s *signer.Signer) (string, error) {
+	res, err := mu.SpawnProcessResult(module, data, tags, s)
 	if err != nil {
 		return "", err
 	}
-	var res SendMessageResponse
+	return res.ProcessID, nil
+}
+
+// SpawnResult describes a spawned process as reported by the MU.
+type SpawnResult struct {
+	// ProcessID is the ID of the process, which is the ID of its signed spawn data item.
+	ProcessID string
+	// Timestamp is the scheduler timestamp of the spawn in milliseconds, or 0 if the MU did not report it.
+	Timestamp int64
+	// BlockHeight is the Arweave block height of the assignment, or 0 if the MU did not report it.
+	BlockHeight int64
+	// Assignment is the ID of the scheduler assignment, if the MU reported it.
+	Assignment string
+	// DataItemID is the ID of the signed spawn data item computed locally before it was sent; see DataItemID.
+	DataItemID string
+}
+
+func (r *SpawnResult) UnmarshalJSON(b []byte) error {
+	var res struct {
+		ID          string    `json:"id"`
+		Timestamp   flexInt64 `json:"timestamp"`
+		BlockHeight flexInt64 `json:"block-height"`
+		Assignment  string    `json:"assignment"`
+	}
+	if err := json.Unmarshal(b, &res); err != nil {
+		return err
+	}
+	*r = SpawnResult{ProcessID: res.ID, Timestamp: int64(res.Timestamp), BlockHeight: int64(res.BlockHeight), Assignment: res.Assignment}
+	return nil
+}
+
+// SpawnProcessResult is like SpawnProcess but also returns the timestamp, block height and assignment the MU
+// reported for the spawn, which helps correlate it with on-chain data.
+func (mu *MU) SpawnProcessResult(module string, data []byte, tags []tag.Tag, s *signer.Signer) (*SpawnResult, error) {
+	return mu.spawnProcess(context.Background(), module, data, tags, itemSigner(s))
+}
+
+func (mu *MU) spawnProcess(ctx context.Context, module string, data []byte, tags []tag.Tag, s Signer) (*SpawnResult, error) {
+	dataItem, err := mu.spawnItem(module, data, tags, s)
+	if err != nil {
+		return nil, err
+	}
+	resp, b, err := mu.send(ctx, "POST", "", dataItem.Raw, nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, failedWith("spawn failed", newAOError(UnitMU, resp, b, mu.maxErrorBody), s)
+	}
+	var res SpawnResult
 	err = json.Unmarshal(b, &res)
 	if err != nil {
-		return "", fmt.Errorf("failed to unmarshal response: %v", err)
+		return nil, unmarshalError("response", err, b, mu.maxErrorBody)
+	}
+	res.DataItemID = dataItem.ID
+	if err := mu.verifyID(ctx, res.ProcessID, dataItem.ID); err != nil {
+		return nil, err
 	}
 
-	return res.ID, nil
+	return &res, nil
 }
 
-func (mu *MU) SpawnProcess(module string, data []byte, tags []tag.Tag, s *signer.Signer) (string, error) {
+// spawnItem signs the data item that spawns a process of module, whose ID is the ID of the process. The protocol,
+// Scheduler and SDK tags are added unless tags already sets them.
+func (mu *MU) spawnItem(module string, data []byte, tags []tag.Tag, s Signer) (*data_item.DataItem, error) {
+	if s == nil {
+		return nil, fmt.Errorf("%w: signer is required", ErrInvalidSigner)
+	}
+	if err := validateID("module", module); err != nil {
+		return nil, err
+	}
 	if data == nil {
 		data = []byte("1984")
 	}
 
 	// Initialize newTags with the base tags
-	newTags := []tag.Tag{
-		{Name: "Data-Protocol", Value: "ao"},
-		{Name: "Variant", Value: "ao.TN.1"},
-		{Name: "Type", Value: "Process"},
-		{Name: "Scheduler", Value: SCHEDULER},
-		{Name: "Module", Value: module},
-		{Name: "SDK", Value: SDK},
-	}
+	newTags := missingTags(tags, append(protocolTags("Process", mu.variant), tag.Tag{Name: "Scheduler", Value: SCHEDULER})...)
+	newTags = append(newTags, tag.Tag{Name: "Module", Value: module})
+	newTags = append(newTags, missingTags(tags, mu.sdkTags()...)...)
 
 	newTags = append(newTags, tags...)
 
 	dataItem := data_item.New(data, "", "", &newTags)
-	err := dataItem.Sign(s)
+	if err := signDataItem(dataItem, s); err != nil {
+		return nil, err
+	}
+	return dataItem, nil
+}
+
+// Monitor asks the MU to start pushing the cron messages of process. It returns the ID of the signed monitor request.
+func (mu *MU) Monitor(process string, s *signer.Signer) (string, error) {
+	dataItem, err := mu.monitorItem(process, itemSigner(s))
 	if err != nil {
 		return "", err
 	}
-	req, err := http.NewRequest("POST", mu.url, bytes.NewBuffer(dataItem.Raw))
+	resp, b, err := mu.send(context.Background(), "POST", "/monitor/"+process, dataItem.Raw, nil)
 	if err != nil {
 		return "", err
 	}
-	req.Header.Set("content-type", "application/octet-stream")
-	req.Header.Set("accept", "application/json")
+	if resp.StatusCode >= http.StatusBadRequest {
+		return "", fmt.Errorf("monitor failed: %w", newAOError(UnitMU, resp, b, mu.maxErrorBody))
+	}
+
+	// The MU may answer with plain text; the ID of the signed request identifies the monitor either way.
+	var res SendMessageResponse
+	if json.Unmarshal(b, &res) == nil && res.ID != "" {
+		return res.ID, nil
+	}
+	return dataItem.ID, nil
+}
 
-	resp, err := mu.client.Do(req)
+// Unmonitor asks the MU to stop pushing the cron messages of process.
+// If no monitor is running the returned error matches ErrMonitorNotFound, so callers can treat stopping
+// an already stopped monitor as a no-op.
+func (mu *MU) Unmonitor(process string, s *signer.Signer) error {
+	dataItem, err := mu.monitorItem(process, itemSigner(s))
 	if err != nil {
-		return "", err
+		return err
 	}
-	defer resp.Body.Close()
-	b, err := io.ReadAll(resp.Body)
+	resp, b, err := mu.send(context.Background(), "DELETE", "/monitor/"+process, dataItem.Raw, nil)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("unmonitor failed: %w: %w", ErrMonitorNotFound, newAOError(UnitMU, resp, b, mu.maxErrorBody))
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("unmonitor failed: %w", newAOError(UnitMU, resp, b, mu.maxErrorBody))
+	}
+	return nil
+}
+
+// AssignOptions tunes an assignment request.
+type AssignOptions struct {
+	// BaseLayer marks message as an Arweave L1 transaction rather than a bundled data item.
+	BaseLayer bool
+	// Exclude lists data fields the CU should not load when evaluating the assigned message.
+	Exclude []string
+}
+
+// Assign schedules the already posted message onto process without uploading its data again.
+// The assignment is created by the process' scheduler, so the request carries no signed data item.
+// The MU answers with the ID of the new assignment, which is returned verbatim and can be used with LoadResult.
+func (mu *MU) Assign(process string, message string, opts AssignOptions) (string, error) {
+	query := url.Values{}
+	query.Set("process-id", process)
+	query.Set("assign", message)
+	if opts.BaseLayer {
+		query.Set("base-layer", "")
+	}
+	if len(opts.Exclude) > 0 {
+		query.Set("exclude", strings.Join(opts.Exclude, ","))
+	}
+
+	resp, b, err := mu.send(context.Background(), "POST", "/?"+query.Encode(), nil, nil)
 	if err != nil {
 		return "", err
 	}
 	if resp.StatusCode >= http.StatusBadRequest {
-		return "", fmt.Errorf("request failed: %s", resp.Status)
+		return "", fmt.Errorf("assign failed: %w", newAOError(UnitMU, resp, b, mu.maxErrorBody))
 	}
-	var res SpawnProcessResponse
+	var res SendMessageResponse
 	err = json.Unmarshal(b, &res)
 	if err != nil {
-		return "", fmt.Errorf("failed to unmarshal response: %v", err)
+		return "", unmarshalError("response", err, b, mu.maxErrorBody)
 	}
-
 	return res.ID, nil
 }
+
+// sdkTags returns the SDK and SDK-Version tags that identify this SDK, or none with WithoutSDKTags.
+func (mu *MU) sdkTags() []tag.Tag {
+	if mu.noSDKTags {
+		return nil
+	}
+	return []tag.Tag{{Name: "SDK", Value: SDK}, {Name: "SDK-Version", Value: Version}}
+}
+
+// monitorItem signs the data item used to start or stop the monitor of process.
+func (mu *MU) monitorItem(process string, s Signer) (*data_item.DataItem, error) {
+	if s == nil {
+		return nil, fmt.Errorf("%w: signer is required", ErrInvalidSigner)
+	}
+	tags := append(protocolTags("Monitor", mu.variant), mu.sdkTags()...)
+
+	dataItem := data_item.New([]byte("1984"), process, "", &tags)
+	err := signDataItem(dataItem, s)
+	if err != nil {
+		return nil, err
+	}
+	return dataItem, nil
+}
+
+// send submits a signed data item to path on the MU, retrying on 5xx and transport errors according to the MU
+// retry policy.
+// Every attempt first waits for the rate limiter, if one is set, and every retry first asks accepted, if set,
+// whether an earlier attempt went through; if so send fails with errScheduled instead of sending again.
+// It returns the final response together with its fully read body.
+func (mu *MU) send(ctx context.Context, method string, path string, raw []byte, accepted func(ctx context.Context) (bool, error)) (*http.Response, []byte, error) {
+	if mu.maxItemSize > 0 && int64(len(raw)) > mu.maxItemSize {
+		return nil, nil, fmt.Errorf("%w: %d bytes, the limit is %d", ErrDataItemTooLarge, len(raw), mu.maxItemSize)
+	}
+	ctx, cancel := requestContext(ctx, mu.timeout)
+	defer cancel()
+	attempt := 0
+	resp, err := mu.breaker.do(mu.url, func() (*http.Response, error) {
+		return mu.retry.do(ctx, mu.roundTrip, func() (*http.Request, error) {
+			attempt++
+			if attempt > 1 && accepted != nil {
+				// A failed check falls back to sending again.
+				if ok, err := accepted(ctx); err == nil && ok {
+					return nil, errScheduled
+				}
+			}
+			if mu.limiter != nil {
+				if err := mu.limiter.Wait(ctx); err != nil {
+					return nil, &unsentError{fmt.Errorf("rate limit: %w", err)}
+				}
+			}
+			req, err := http.NewRequestWithContext(ctx, method, mu.url+path, bytes.NewReader(raw))
+			if err != nil {
+				return nil, &unsentError{err}
+			}
+			withProgress(ctx, req, raw)
+			req.Header.Set("content-type", "application/octet-stream")
+			req.Header.Set("accept", "application/json")
+			return req, nil
+		}, retryServerError)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	b, err := readBody(resp.Body, mu.maxResponseBytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp, b, nil
+}
+
+// errScheduled stops the retries of a message the SU already has.
+var errScheduled = errors.New("message already scheduled")
+
+// alreadyScheduled returns the check send makes before retrying message id on process, or nil if the client
+// does not check.
+func (mu *MU) alreadyScheduled(process string, id string) func(ctx context.Context) (bool, error) {
+	if mu.scheduled == nil {
+		return nil
+	}
+	return func(ctx context.Context) (bool, error) {
+		return mu.scheduled(ctx, process, id)
+	}
+}
+
+func (mu *MU) roundTrip(req *http.Request) (*http.Response, error) {
+	return sendRequest(mu.client, mu.logger, mu.observer, UnitMU, mu.header, req)
+}
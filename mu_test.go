@@ -1,12 +1,20 @@
 package aogo
 
 import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/liteseed/goar/signer"
 	"github.com/liteseed/goar/tag"
+	"github.com/liteseed/goar/transaction/data_item"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -19,7 +27,7 @@ func NewMUMock(URL string) MU {
 
 func TestSendMessage(t *testing.T) {
 	t.Run("0", func(t *testing.T) {
-		process := "yugMfaR-u_11GkAuZhqeChPuzoxVYuJW8RnNCIby-D8"
+		process := testProcess
 		data := ""
 		tags := &[]tag.Tag{{Name: "Action", Value: "Stakers"}}
 
@@ -53,7 +61,7 @@ func TestSendMessage(t *testing.T) {
 		s, err := signer.FromPath("./keys/wallet.json") // Mock signer or use a real one for the test
 		assert.NoError(t, err)
 
-		id, err := ao.SendMessage("process", "data", nil, "", s)
+		id, err := ao.SendMessage(testProcess, "data", nil, "", s)
 		assert.NoError(t, err)
 		assert.Equal(t, "mockMessageID", id)
 	})
@@ -76,7 +84,7 @@ func TestSpawnProcess(t *testing.T) {
 		s, err := signer.FromPath("./keys/wallet.json")
 		assert.NoError(t, err)
 
-		res, err := mu.SpawnProcess("", nil, tags, s)
+		res, err := mu.SpawnProcess(testModule, nil, tags, s)
 
 		assert.NoError(t, err)
 		assert.True(t, res != "")
@@ -96,8 +104,286 @@ func TestSpawnProcess(t *testing.T) {
 		signer, err := signer.FromPath("./keys/wallet.json") // Mock signer or use a real one for the test
 		assert.NoError(t, err)
 
-		id, err := ao.SpawnProcess("module", []byte("data"), nil, signer)
+		id, err := ao.SpawnProcess(testModule, []byte("data"), nil, signer)
 		assert.NoError(t, err)
 		assert.Equal(t, "mockProcessID", id)
 	})
 }
+
+func TestSpawnProcessResult(t *testing.T) {
+	t.Run("Full", func(t *testing.T) {
+		var posted string
+		muServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			b, err := io.ReadAll(r.Body)
+			assert.NoError(t, err)
+			item, err := data_item.Decode(b)
+			assert.NoError(t, err)
+			posted = item.ID
+			w.WriteHeader(http.StatusAccepted)
+			_, err = w.Write([]byte(`{"message": "Processing DataItem", "id": "mockProcessID", "timestamp": 1717431046567, "block-height": "1434187", "assignment": "mockAssignmentID"}`))
+			assert.NoError(t, err)
+		}))
+		defer muServer.Close()
+
+		s, err := signer.FromPath("./keys/wallet.json")
+		assert.NoError(t, err)
+
+		ao := &AO{mu: newMU(muServer.URL)}
+		res, err := ao.SpawnProcessResult(testModule, nil, nil, s)
+		assert.NoError(t, err)
+		assert.Equal(t, &SpawnResult{ProcessID: "mockProcessID", Timestamp: 1717431046567, BlockHeight: 1434187, Assignment: "mockAssignmentID", DataItemID: posted}, res)
+	})
+	t.Run("IDOnly", func(t *testing.T) {
+		var r SpawnResult
+		assert.NoError(t, json.Unmarshal([]byte(`{"id": "mockProcessID"}`), &r))
+		assert.Equal(t, SpawnResult{ProcessID: "mockProcessID"}, r)
+	})
+	t.Run("InvalidHeight", func(t *testing.T) {
+		var r SpawnResult
+		err := json.Unmarshal([]byte(`{"id": "mockProcessID", "block-height": "tip"}`), &r)
+		assert.ErrorContains(t, err, `invalid integer value "tip"`)
+	})
+}
+
+func TestSendMessageResult(t *testing.T) {
+	var posted string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		posted, err = DataItemID(b)
+		assert.NoError(t, err)
+		_, err = w.Write([]byte(`{"id": "` + posted + `"}`))
+		assert.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	s, err := signer.FromPath("./keys/wallet.json")
+	assert.NoError(t, err)
+
+	t.Run("HTTP", func(t *testing.T) {
+		ao, err := New(WithMUURL(srv.URL))
+		assert.NoError(t, err)
+		res, err := ao.SendMessageResult(testProcess, "data", nil, "", s)
+		assert.NoError(t, err)
+		assert.Equal(t, posted, res.ID)
+		assert.Equal(t, posted, res.DataItemID)
+	})
+
+	t.Run("MessengerUnit", func(t *testing.T) {
+		ao, err := NewWithUnits(nil, &stubMU{})
+		assert.NoError(t, err)
+		res, err := ao.SendMessageResult(testProcess, "data", nil, "", s)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, res.ID)
+		assert.Empty(t, res.DataItemID)
+	})
+}
+
+func TestMUTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer srv.Close()
+
+	ao, err := New(WithMUURL(srv.URL), WithMUTimeout(20*time.Millisecond))
+	assert.NoError(t, err)
+
+	s, err := signer.FromPath("./keys/wallet.json")
+	assert.NoError(t, err)
+
+	_, err = ao.SendMessage(testProcess, "data", nil, "", s)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestMonitor(t *testing.T) {
+	process := testProcess
+	s, err := signer.FromPath("./keys/wallet.json")
+	assert.NoError(t, err)
+
+	t.Run("Success", func(t *testing.T) {
+		muServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "/monitor/"+process, r.URL.Path)
+			w.WriteHeader(http.StatusAccepted)
+			_, err := w.Write([]byte("Success"))
+			assert.NoError(t, err)
+		}))
+		defer muServer.Close()
+
+		ao := &AO{mu: newMU(muServer.URL)}
+		id, err := ao.Monitor(process, s)
+		assert.NoError(t, err)
+		assert.Len(t, id, 43)
+	})
+
+	t.Run("Rejected", func(t *testing.T) {
+		muServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			_, err := w.Write([]byte("process has no cron"))
+			assert.NoError(t, err)
+		}))
+		defer muServer.Close()
+
+		ao := &AO{mu: newMU(muServer.URL)}
+		_, err := ao.Monitor(process, s)
+		assert.ErrorContains(t, err, "process has no cron")
+	})
+}
+
+func TestUnmonitor(t *testing.T) {
+	process := testProcess
+	s, err := signer.FromPath("./keys/wallet.json")
+	assert.NoError(t, err)
+
+	t.Run("Success", func(t *testing.T) {
+		muServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodDelete, r.Method)
+			assert.Equal(t, "/monitor/"+process, r.URL.Path)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer muServer.Close()
+
+		ao := &AO{mu: newMU(muServer.URL)}
+		assert.NoError(t, ao.Unmonitor(process, s))
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		muServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer muServer.Close()
+
+		ao := &AO{mu: newMU(muServer.URL)}
+		err := ao.Unmonitor(process, s)
+		assert.True(t, errors.Is(err, ErrMonitorNotFound))
+	})
+
+	t.Run("Rejected", func(t *testing.T) {
+		muServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer muServer.Close()
+
+		ao := &AO{mu: newMU(muServer.URL)}
+		err := ao.Unmonitor(process, s)
+		assert.Error(t, err)
+		assert.False(t, errors.Is(err, ErrMonitorNotFound))
+	})
+}
+
+func TestAssign(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		muServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "process", r.URL.Query().Get("process-id"))
+			assert.Equal(t, "tx", r.URL.Query().Get("assign"))
+			assert.True(t, r.URL.Query().Has("base-layer"))
+			assert.Equal(t, "Data,Anchor", r.URL.Query().Get("exclude"))
+			_, err := w.Write([]byte(`{"id": "mockAssignmentID"}`))
+			assert.NoError(t, err)
+		}))
+		defer muServer.Close()
+
+		ao := &AO{mu: newMU(muServer.URL)}
+		id, err := ao.Assign("process", "tx", AssignOptions{BaseLayer: true, Exclude: []string{"Data", "Anchor"}})
+		assert.NoError(t, err)
+		assert.Equal(t, "mockAssignmentID", id)
+	})
+
+	t.Run("Rejected", func(t *testing.T) {
+		muServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.False(t, r.URL.Query().Has("base-layer"))
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer muServer.Close()
+
+		ao := &AO{mu: newMU(muServer.URL)}
+		_, err := ao.Assign("process", "tx", AssignOptions{})
+		assert.Error(t, err)
+	})
+}
+
+func TestMURateLimit(t *testing.T) {
+	s, err := signer.FromPath("./keys/wallet.json")
+	assert.NoError(t, err)
+	muServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"id": "mockMessageID"}`))
+		assert.NoError(t, err)
+	}))
+	defer muServer.Close()
+
+	t.Run("Waits", func(t *testing.T) {
+		ao, err := New(WithMUURL(muServer.URL), WithRateLimit(20, 1))
+		assert.NoError(t, err)
+
+		start := time.Now()
+		for i := 0; i < 3; i++ {
+			_, err := ao.SendMessage(testProcess, "data", nil, "", s)
+			assert.NoError(t, err)
+		}
+		assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+	})
+
+	t.Run("HonorsDeadline", func(t *testing.T) {
+		ao, err := New(WithMUURL(muServer.URL), WithRateLimit(0.1, 1), WithMUTimeout(50*time.Millisecond))
+		assert.NoError(t, err)
+
+		_, err = ao.SendMessage(testProcess, "data", nil, "", s)
+		assert.NoError(t, err)
+		start := time.Now()
+		_, err = ao.SendMessage(testProcess, "data", nil, "", s)
+		assert.ErrorContains(t, err, "rate limit")
+		assert.Less(t, time.Since(start), time.Second)
+	})
+}
+
+func TestSendMessageBytes(t *testing.T) {
+	payload := []byte{0x00, 0xff, 0xfe, 0x80, 0x81, 'a', 0xc3}
+	var got []byte
+	muServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		item, err := data_item.Decode(b)
+		assert.NoError(t, err)
+		got, err = base64.RawURLEncoding.DecodeString(item.Data)
+		assert.NoError(t, err)
+		_, err = w.Write([]byte(`{"id": "mockMessageID"}`))
+		assert.NoError(t, err)
+	}))
+	defer muServer.Close()
+
+	s, err := signer.FromPath("./keys/wallet.json")
+	assert.NoError(t, err)
+
+	ao := &AO{mu: newMU(muServer.URL)}
+	id, err := ao.SendMessageBytes(testProcess, payload, nil, "", s)
+	assert.NoError(t, err)
+	assert.Equal(t, "mockMessageID", id)
+	assert.Equal(t, payload, got)
+}
+
+func TestWithMaxDataItemSize(t *testing.T) {
+	requests := 0
+	muServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, err := w.Write([]byte(`{"id": "mockMessageID"}`))
+		assert.NoError(t, err)
+	}))
+	defer muServer.Close()
+
+	ao, err := New(WithMUURL(muServer.URL), WithMaxDataItemSize(4096), WithSigner(setupSigner(t)))
+	assert.NoError(t, err)
+
+	_, err = ao.SendMessage(testProcess, "small", nil, "", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, requests)
+
+	_, err = ao.SendMessage(testProcess, strings.Repeat("a", 4096), nil, "", nil)
+	assert.ErrorIs(t, err, ErrDataItemTooLarge)
+	_, err = ao.SpawnProcess(testModule, make([]byte, 4096), nil, nil)
+	assert.ErrorIs(t, err, ErrDataItemTooLarge)
+	assert.Equal(t, 1, requests)
+}
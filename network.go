@@ -0,0 +1,23 @@
+package aogo
+
+// Network holds the canonical unit URLs of an AO network. Use it with WithNetwork.
+//
+// There is no mainnet preset: AO mainnet units run HyperBEAM, whose routes differ from the legacynet CU, MU and SU
+// routes this client speaks.
+type Network struct {
+	Name       string
+	CUURL      string
+	MUURL      string
+	SUURL      string
+	GatewayURL string
+}
+
+// Legacynet is the original AO testnet, which New uses by default. WithNetwork(Legacynet) restores its URLs after
+// options that changed them.
+var Legacynet = Network{
+	Name:       "legacynet",
+	CUURL:      CuUrl,
+	MUURL:      MuUrl,
+	SUURL:      SuUrl,
+	GatewayURL: GATEWAY,
+}
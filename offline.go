@@ -0,0 +1,71 @@
+package aogo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/liteseed/goar/signer"
+	"github.com/liteseed/goar/tag"
+)
+
+// SignSpawn returns the signed ANS-104 data item that spawns a process of module, exactly as SpawnProcess
+// would post it, without sending it. This separates signing, for example on an air-gapped host, from
+// submission. The ID of the process is the ID of the item; see DataItemID. A nil s falls back to the default
+// signer.
+func (ao *AO) SignSpawn(module string, data []byte, tags []tag.Tag, s *signer.Signer) ([]byte, error) {
+	item, err := ao.mu.spawnItem(module, data, tags, ao.itemSignerOr(s))
+	if err != nil {
+		return nil, err
+	}
+	return item.Raw, nil
+}
+
+// SignMessage returns the signed ANS-104 data item of a message to process, exactly as SendMessage would post
+// it, without sending it. Like SendMessage it uses an automatic anchor if anchor is empty and WithAutoAnchor is
+// set. tags is not modified. A nil s falls back to the default signer.
+func (ao *AO) SignMessage(process string, data []byte, tags []tag.Tag, anchor string, s *signer.Signer) ([]byte, error) {
+	t := append([]tag.Tag{}, tags...)
+	item, err := ao.mu.messageItem(process, data, &t, anchor, ao.itemSignerOr(s))
+	if err != nil {
+		return nil, err
+	}
+	return item.Raw, nil
+}
+
+// SubmitDataItem posts item, a signed ANS-104 data item such as one returned by SignMessage or SignSpawn, to the
+// MU as is and returns the ID the MU answered with. The bytes are neither re-signed nor modified; item is only
+// checked to have a supported signature type. With WithVerifyIDs the answer must match DataItemID(item).
+func (ao *AO) SubmitDataItem(item []byte) (string, error) {
+	return ao.SubmitDataItemContext(context.Background(), item)
+}
+
+// SubmitDataItemContext is like SubmitDataItem but aborts when ctx is done and traces the call under ctx.
+func (ao *AO) SubmitDataItemContext(ctx context.Context, item []byte) (id string, err error) {
+	ctx, span := ao.startSpan(ctx, "SubmitDataItem", UnitMU, "")
+	defer func() { span.End(err) }()
+	return ao.mu.submitDataItem(ctx, item)
+}
+
+func (mu *MU) submitDataItem(ctx context.Context, item []byte) (string, error) {
+	signed, err := DataItemID(item)
+	if err != nil {
+		return "", fmt.Errorf("invalid data item: %w", err)
+	}
+	resp, b, err := mu.send(ctx, "POST", "", item, nil)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return "", fmt.Errorf("submit failed: %w", newAOError(UnitMU, resp, b, mu.maxErrorBody))
+	}
+	var res SendMessageResponse
+	if err := json.Unmarshal(b, &res); err != nil {
+		return "", unmarshalError("response", err, b, mu.maxErrorBody)
+	}
+	if err := mu.verifyID(ctx, res.ID, signed); err != nil {
+		return "", err
+	}
+	return res.ID, nil
+}
@@ -0,0 +1,113 @@
+package aogo
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/liteseed/goar/tag"
+	"github.com/liteseed/goar/transaction/data_item"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignSpawn(t *testing.T) {
+	// No unit is reachable: signing must not touch the network.
+	ao, err := New(WithMUURL("http://127.0.0.1:0"))
+	assert.NoError(t, err)
+	s := setupSigner(t)
+
+	raw, err := ao.SignSpawn(testModule, []byte("code"), []tag.Tag{{Name: "Name", Value: "test"}}, s)
+	assert.NoError(t, err)
+	item, err := data_item.Decode(raw)
+	assert.NoError(t, err)
+	assert.Contains(t, *item.Tags, tag.Tag{Name: "Type", Value: "Process"})
+	assert.Contains(t, *item.Tags, tag.Tag{Name: "Module", Value: testModule})
+	assert.Contains(t, *item.Tags, tag.Tag{Name: "Name", Value: "test"})
+	assert.Empty(t, item.Target)
+
+	_, err = ao.SignSpawn("short", nil, nil, s)
+	assert.ErrorIs(t, err, ErrInvalidID)
+	_, err = ao.SignSpawn(testModule, nil, nil, nil)
+	assert.ErrorIs(t, err, ErrInvalidSigner)
+}
+
+func TestSignMessage(t *testing.T) {
+	ao, err := New(WithMUURL("http://127.0.0.1:0"), WithAutoAnchor(AnchorCounter), WithSigner(setupSigner(t)))
+	assert.NoError(t, err)
+
+	tags := []tag.Tag{{Name: "Action", Value: "Eval"}}
+	raw, err := ao.SignMessage(testProcess, []byte("return 1"), tags, "", nil)
+	assert.NoError(t, err)
+	assert.Len(t, tags, 1)
+
+	item, err := data_item.Decode(raw)
+	assert.NoError(t, err)
+	assert.Equal(t, testProcess, item.Target)
+	assert.Len(t, item.Anchor, 32)
+	assert.Contains(t, *item.Tags, tag.Tag{Name: "Action", Value: "Eval"})
+	assert.Contains(t, *item.Tags, tag.Tag{Name: "Type", Value: "Message"})
+	data, err := base64.RawURLEncoding.DecodeString(item.Data)
+	assert.NoError(t, err)
+	assert.Equal(t, "return 1", string(data))
+
+	id, err := DataItemID(raw)
+	assert.NoError(t, err)
+	assert.Equal(t, item.ID, id)
+
+	_, err = ao.SignMessage("short", nil, nil, "", nil)
+	assert.ErrorIs(t, err, ErrInvalidID)
+}
+
+func TestSubmitDataItem(t *testing.T) {
+	var received []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/octet-stream", r.Header.Get("Content-Type"))
+		b, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		received = b
+		if bytes.Contains(b, []byte("bad")) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, err = w.Write([]byte(`{"id": "` + testMessage + `"}`))
+		assert.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	ao, err := New(WithMUURL(srv.URL), WithSigner(setupSigner(t)))
+	assert.NoError(t, err)
+	raw, err := ao.SignMessage(testProcess, []byte("data"), nil, "", nil)
+	assert.NoError(t, err)
+
+	t.Run("Verbatim", func(t *testing.T) {
+		id, err := ao.SubmitDataItem(raw)
+		assert.NoError(t, err)
+		assert.Equal(t, testMessage, id)
+		assert.Equal(t, raw, received)
+	})
+
+	t.Run("Rejected", func(t *testing.T) {
+		bad, err := ao.SignMessage(testProcess, []byte("bad"), nil, "", nil)
+		assert.NoError(t, err)
+		_, err = ao.SubmitDataItem(bad)
+		var aoErr *AOError
+		assert.ErrorAs(t, err, &aoErr)
+	})
+
+	t.Run("Verified", func(t *testing.T) {
+		ao, err := New(WithMUURL(srv.URL), WithVerifyIDs())
+		assert.NoError(t, err)
+		_, err = ao.SubmitDataItem(raw)
+		assert.ErrorIs(t, err, ErrIDMismatch)
+	})
+
+	t.Run("Malformed", func(t *testing.T) {
+		received = nil
+		_, err := ao.SubmitDataItem([]byte{9, 0})
+		assert.ErrorContains(t, err, "invalid data item")
+		assert.Nil(t, received)
+	})
+}
@@ -0,0 +1,497 @@
+package aogo
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/liteseed/goar/signer"
+	"golang.org/x/time/rate"
+)
+
+// Option configures an AO client created with New.
+type Option func(*AO)
+
+// WithNetwork points the client at the units of network, e.g. Legacynet. Options that follow it, such as
+// WithCUURL, still override individual URLs. Empty URLs in network keep the current ones.
+func WithNetwork(network Network) Option {
+	return func(ao *AO) {
+		if network.CUURL != "" {
+			WithCUURL(network.CUURL)(ao)
+		}
+		if network.MUURL != "" {
+			ao.mu.url = network.MUURL
+		}
+		if network.SUURL != "" {
+			ao.su.url = network.SUURL
+		}
+		if network.GatewayURL != "" {
+			ao.gateway.url = network.GatewayURL
+		}
+	}
+}
+
+// WithCUURL sets the Compute Unit used by LoadResult and DryRun.
+func WithCUURL(url string) Option {
+	return func(ao *AO) {
+		ao.cu.url = url
+		ao.cu.urls = nil
+	}
+}
+
+// WithCUURLs sets several Compute Units. Requests go to one of them and fail over to the next, in
+// round-robin order, when it cannot be reached or answers with a 5xx status.
+func WithCUURLs(urls []string) Option {
+	return func(ao *AO) {
+		if len(urls) == 0 {
+			return
+		}
+		ao.cu.url = urls[0]
+		ao.cu.urls = append([]string{}, urls...)
+	}
+}
+
+// WithMUURL sets the Messenger Unit used by SpawnProcess and SendMessage.
+func WithMUURL(url string) Option {
+	return func(ao *AO) {
+		ao.mu.url = url
+	}
+}
+
+// WthMU sets the Messenger Unit URL.
+//
+// Deprecated: use WithMUURL.
+func WthMU(url string) Option {
+	return WithMUURL(url)
+}
+
+// WthCU sets the Compute Unit URL.
+//
+// Deprecated: use WithCUURL.
+func WthCU(url string) Option {
+	return WithCUURL(url)
+}
+
+// WithSUURL sets the Scheduler Unit used by GetMessages.
+func WithSUURL(url string) Option {
+	return func(ao *AO) {
+		ao.su.url = url
+	}
+}
+
+// WithGatewayURL sets the Arweave gateway used for GraphQL lookups.
+func WithGatewayURL(url string) Option {
+	return func(ao *AO) {
+		ao.gateway.url = url
+	}
+}
+
+// WithHTTPClient sets the http.Client used for CU, MU, SU and gateway requests.
+// Passing nil keeps http.DefaultClient.
+func WithHTTPClient(client *http.Client) Option {
+	return func(ao *AO) {
+		if client == nil {
+			client = http.DefaultClient
+		}
+		ao.cu.client = client
+		ao.mu.client = client
+		ao.su.client = client
+		ao.gateway.client = client
+	}
+}
+
+// WithProxy sends every unit request through the HTTP proxy at proxyURL, e.g. "http://proxy.internal:3128".
+// Without it the default transport already honors HTTP_PROXY, HTTPS_PROXY and NO_PROXY; WithProxy overrides
+// them. It applies to the client set by WithHTTPClient, whose transport must then be an *http.Transport.
+func WithProxy(proxyURL string) Option {
+	return func(ao *AO) {
+		u, err := url.Parse(proxyURL)
+		if err == nil && (u.Scheme == "" || u.Host == "") {
+			err = errors.New("missing scheme or host")
+		}
+		if err != nil {
+			if ao.err == nil {
+				ao.err = fmt.Errorf("invalid proxy URL: %w", err)
+			}
+			return
+		}
+		ao.transport = append(ao.transport, func(t *http.Transport) {
+			t.Proxy = http.ProxyURL(u)
+		})
+	}
+}
+
+// WithTransportTuning sets the connection pool of every unit client: at most maxIdleConns idle connections
+// overall and maxIdleConnsPerHost per unit host, each closed after idleConnTimeout unused. Zero arguments take
+// DefaultMaxIdleConns, DefaultMaxIdleConnsPerHost and DefaultIdleConnTimeout, tuned for many requests to few
+// hosts, which avoids exhausting ephemeral ports under concurrency. It applies to the client set by
+// WithHTTPClient, whose transport must then be an *http.Transport.
+func WithTransportTuning(maxIdleConns int, maxIdleConnsPerHost int, idleConnTimeout time.Duration) Option {
+	return func(ao *AO) {
+		if maxIdleConns == 0 {
+			maxIdleConns = DefaultMaxIdleConns
+		}
+		if maxIdleConnsPerHost == 0 {
+			maxIdleConnsPerHost = DefaultMaxIdleConnsPerHost
+		}
+		if idleConnTimeout == 0 {
+			idleConnTimeout = DefaultIdleConnTimeout
+		}
+		ao.transport = append(ao.transport, func(t *http.Transport) {
+			t.MaxIdleConns = maxIdleConns
+			t.MaxIdleConnsPerHost = maxIdleConnsPerHost
+			t.IdleConnTimeout = idleConnTimeout
+		})
+	}
+}
+
+// WithTLSConfig sets the TLS configuration of every unit connection, e.g. RootCAs holding an internal CA or
+// Certificates for mTLS. config is cloned, so later changes have no effect. Setting InsecureSkipVerify is
+// supported for local testing but strongly discouraged anywhere else, as it lets anyone impersonate the units.
+// It applies to the client set by WithHTTPClient, whose transport must then be an *http.Transport.
+func WithTLSConfig(config *tls.Config) Option {
+	return func(ao *AO) {
+		config := config.Clone()
+		ao.transport = append(ao.transport, func(t *http.Transport) {
+			t.TLSClientConfig = config
+		})
+	}
+}
+
+// WithMiddleware wraps the transport of the CU, MU, SU and gateway clients with middleware, the first one being
+// the outermost. Middleware wraps rather than replaces the client's transport, runs below retries and
+// failover, and applies to the client set by WithHTTPClient regardless of the order of options.
+func WithMiddleware(middleware ...Middleware) Option {
+	return func(ao *AO) {
+		ao.middleware = append(ao.middleware, middleware...)
+	}
+}
+
+// WithCompression gzips the body of every unit request, such as the data items posted to the MU, and marks it
+// with Content-Encoding: gzip. Only enable it for units that accept compressed requests. Responses are
+// decompressed whether or not it is set.
+func WithCompression() Option {
+	return func(ao *AO) {
+		ao.middleware = append(ao.middleware, compressRequests)
+	}
+}
+
+// WithUserAgent sets the User-Agent of every unit request, replacing DefaultUserAgent.
+func WithUserAgent(userAgent string) Option {
+	return func(ao *AO) {
+		ao.userAgent = userAgent
+	}
+}
+
+// WithHeader adds a header to every unit request. Headers the SDK sets itself, such as content-type, are kept.
+func WithHeader(name string, value string) Option {
+	return func(ao *AO) {
+		if ao.headers == nil {
+			ao.headers = http.Header{}
+		}
+		ao.headers.Add(name, value)
+	}
+}
+
+// WithTracer traces SpawnProcess, SendMessage, LoadResult and DryRun calls with tracer. See the aootel package
+// for an OpenTelemetry tracer.
+func WithTracer(tracer Tracer) Option {
+	return func(ao *AO) {
+		ao.tracer = tracer
+	}
+}
+
+// WithLogger logs every CU, MU, SU and gateway request at debug level: unit, method, URL, status and latency.
+// Bodies, and with them signed data and keys, are never logged. Without this option nothing is logged.
+func WithLogger(logger *slog.Logger) Option {
+	return func(ao *AO) {
+		ao.cu.logger = logger
+		ao.mu.logger = logger
+		ao.su.logger = logger
+		ao.gateway.logger = logger
+	}
+}
+
+// WithMaxErrorBody sets how many bytes of a response body are kept in errors; longer bodies are truncated.
+// Zero keeps DefaultMaxErrorBody and a negative n keeps whole bodies.
+func WithMaxErrorBody(n int) Option {
+	return func(ao *AO) {
+		ao.cu.maxErrorBody = n
+		ao.mu.maxErrorBody = n
+		ao.su.maxErrorBody = n
+		ao.gateway.maxErrorBody = n
+	}
+}
+
+// WithMaxResponseBytes sets the largest response body read from any unit, protecting against endpoints that
+// answer with enormous bodies. Requests whose response exceeds it fail with ErrResponseTooLarge. Zero keeps
+// DefaultMaxResponseBytes and a negative n removes the limit. LoadResultStream is not limited.
+func WithMaxResponseBytes(n int64) Option {
+	return func(ao *AO) {
+		ao.cu.maxResponseBytes = n
+		ao.mu.maxResponseBytes = n
+		ao.su.maxResponseBytes = n
+		ao.gateway.maxResponseBytes = n
+	}
+}
+
+// WithMaxDataItemSize makes SendMessage, SpawnProcess and the other MU calls fail with ErrDataItemTooLarge,
+// without a request, when the signed data item is larger than n bytes, so an upload the MU or a gateway in
+// front of it would reject only after receiving it fails at once. The MU takes a data item in a single POST, so
+// larger data has to be uploaded with UploadData and referenced from the message; WithSpawnDataUpload does so
+// for the data of a process. Zero or less sets no limit.
+func WithMaxDataItemSize(n int64) Option {
+	return func(ao *AO) {
+		ao.mu.maxItemSize = n
+	}
+}
+
+// WithSpawnDataUpload makes SpawnProcess upload data larger than threshold bytes with UploadData, in chunks
+// through the gateway, and spawn the process with an On-Boot tag set to the ID of the upload and the default
+// data in its place, so the process loads the data when it boots. An upload that fails returns an UploadError
+// for resuming it. Zero or less, the default, keeps the data in the spawn data item whatever its size.
+func WithSpawnDataUpload(threshold int64) Option {
+	return func(ao *AO) {
+		ao.spawnUpload = threshold
+	}
+}
+
+// WithCodec decodes CU results with codec instead of JSONCodec and asks the CU for its content type.
+func WithCodec(codec Codec) Option {
+	return func(ao *AO) {
+		ao.cu.codec = codec
+	}
+}
+
+// WithObserver reports every CU, MU, SU and gateway request to observer, and every retry too if it implements
+// RetryObserver.
+func WithObserver(observer Observer) Option {
+	return func(ao *AO) {
+		ao.cu.observer = observer
+		ao.mu.observer = observer
+		ao.su.observer = observer
+		ao.gateway.observer = observer
+	}
+}
+
+// WithTimeout bounds every CU, MU, SU and gateway request by d. A zero duration disables the timeout.
+func WithTimeout(d time.Duration) Option {
+	return func(ao *AO) {
+		ao.cu.timeout = d
+		ao.mu.timeout = d
+		ao.su.timeout = d
+		ao.gateway.timeout = d
+	}
+}
+
+// WithCUTimeout bounds every CU request (LoadResult, DryRun) by d.
+func WithCUTimeout(d time.Duration) Option {
+	return func(ao *AO) {
+		ao.cu.timeout = d
+	}
+}
+
+// WithMUTimeout bounds every MU request (SpawnProcess, SendMessage) by d.
+func WithMUTimeout(d time.Duration) Option {
+	return func(ao *AO) {
+		ao.mu.timeout = d
+	}
+}
+
+// WithCURetry sets how LoadResult retries transport errors and 502, 503 and 504 responses.
+func WithCURetry(policy RetryPolicy) Option {
+	return func(ao *AO) {
+		ao.cu.retry = policy
+	}
+}
+
+// WithMURetry sets how SpawnProcess and SendMessage retry 5xx responses and transport errors.
+// Use NoRetry to send every data item exactly once.
+func WithMURetry(policy RetryPolicy) Option {
+	return func(ao *AO) {
+		ao.mu.retry = policy
+	}
+}
+
+// WithCircuitBreaker makes requests to a unit URL fail fast with ErrCircuitOpen for cooldown once threshold calls
+// in a row have failed with a transport error, a timeout or a 5xx response, sparing callers a full timeout per
+// call while a unit is down. A call counts once, after its retries. When cooldown has passed a single probe call
+// is let through, and its success closes the circuit. With WithCUURLs, a CU whose circuit is open is skipped in
+// favor of the next one. A threshold below 1 disables the breaker.
+func WithCircuitBreaker(threshold int, cooldown time.Duration) Option {
+	return func(ao *AO) {
+		var b *circuitBreaker
+		if threshold >= 1 {
+			b = newCircuitBreaker(threshold, cooldown)
+		}
+		ao.cu.breaker = b
+		ao.mu.breaker = b
+		ao.su.breaker = b
+		ao.gateway.breaker = b
+	}
+}
+
+// WithBackoff sets the backoff between the retries of every unit, replacing the exponential backoff of their
+// retry policies; see Constant and ExponentialJitter. A later WithCURetry or WithMURetry replaces the whole
+// policy of its unit, backoff included.
+func WithBackoff(backoff Backoff) Option {
+	return func(ao *AO) {
+		ao.cu.retry.Backoff = backoff
+		ao.mu.retry.Backoff = backoff
+		ao.su.retry.Backoff = backoff
+		ao.gateway.retry.Backoff = backoff
+	}
+}
+
+// WithRetryIf lets retryIf decide which failed attempts of every unit are retried, for example to never retry
+// SendMessage when a lost response could mean the MU already accepted the message. See RetryPolicy.RetryIf.
+// A later WithCURetry or WithMURetry replaces the whole policy of its unit, predicate included.
+func WithRetryIf(retryIf func(req *http.Request, resp *http.Response, err error) bool) Option {
+	return func(ao *AO) {
+		ao.cu.retry.RetryIf = retryIf
+		ao.mu.retry.RetryIf = retryIf
+		ao.su.retry.RetryIf = retryIf
+		ao.gateway.retry.RetryIf = retryIf
+	}
+}
+
+// WithVerifyIDs makes SendMessage and SpawnProcess check that the MU answers with the ID of the data item the
+// client signed, failing with ErrIDMismatch otherwise, which protects against a MU substituting another
+// message. Mismatches are logged with both IDs to the logger set by WithLogger.
+func WithVerifyIDs() Option {
+	return func(ao *AO) {
+		ao.mu.verifyIDs = true
+	}
+}
+
+// WithIdempotentSends keeps SendMessage from submitting a message twice when an attempt failed without telling
+// whether the MU accepted it, such as on a timeout or a 5xx response.
+//
+// A message is signed once per call, so every attempt carries the same data item and ID. With this option,
+// before each retry the client asks the SU whether it has already scheduled that ID; if it has, SendMessage
+// returns the ID without sending again. If the SU cannot answer, the data item is sent again as without the
+// option. The guarantee covers the retries within one call only: calling SendMessage again signs a new message.
+func WithIdempotentSends() Option {
+	return func(ao *AO) {
+		ao.mu.scheduled = ao.su.hasMessage
+	}
+}
+
+// WithRateLimit caps MU submissions at rps requests per second with bursts of up to burst requests.
+// Calls wait for their turn, and give up once their request context is done.
+func WithRateLimit(rps float64, burst int) Option {
+	return func(ao *AO) {
+		ao.mu.limiter = rate.NewLimiter(rate.Limit(rps), burst)
+	}
+}
+
+// WithAutoAnchor makes SendMessage fill in an anchor when the caller passes an empty one, so repeated
+// messages to a process cannot collide. AnchorCounter keeps one counter per process; use ResetAnchor to restart it.
+func WithAutoAnchor(mode AnchorMode) Option {
+	return func(ao *AO) {
+		ao.mu.anchors = newAnchors(mode)
+	}
+}
+
+// WithOrderedSends makes messages to the same process reach the MU one at a time, in the order SendMessage and
+// its variants were called, even when they are called from several goroutines; messages to different processes
+// are still sent in parallel. The next message to a process is only posted once the MU has answered the one
+// before it, so the throughput to a single process drops to one message per MU round trip, retries included.
+// A caller whose context is done while it waits for its turn gives up without sending.
+func WithOrderedSends() Option {
+	return func(ao *AO) {
+		ao.sends = newSendQueues()
+	}
+}
+
+// WithAutoReference makes SendMessage and its variants add a Reference tag to messages that do not set one,
+// counting from start separately for each process, as AO processes do for the messages they send. It applies
+// to the HTTP MU and to a MessengerUnit set by NewWithUnits alike. Replies carry the reference as their
+// X-Reference tag; SendMessageResult returns it in MessageResult.Reference so they can be matched. The counters
+// live in the client and start again from start in a new one. Without this option no Reference tag is added.
+func WithAutoReference(start uint64) Option {
+	return func(ao *AO) {
+		ao.mu.references = newReferences(start)
+	}
+}
+
+// WithSigner sets the signer used by calls that are passed a nil signer.
+func WithSigner(s *signer.Signer) Option {
+	return func(ao *AO) {
+		ao.signer = s
+	}
+}
+
+// WithSignerBytes is like WithSigner but decodes the signer from the JSON of an Arweave JWK, so a key held
+// in memory never has to be written to disk. New fails with ErrInvalidSigner if the key cannot be decoded.
+func WithSignerBytes(jwk []byte) Option {
+	return func(ao *AO) {
+		s, err := NewSigner(jwk)
+		if err != nil {
+			if ao.err == nil {
+				ao.err = err
+			}
+			return
+		}
+		ao.signer = s
+	}
+}
+
+// WithDataItemSigner sets the signer of the data items sent by SpawnProcess, SendMessage and their variants when
+// they are passed a nil signer, taking precedence over WithSigner. Use it for keys other than Arweave RSA keys,
+// such as Ethereum keys; the items carry the signature type of s.
+func WithDataItemSigner(s Signer) Option {
+	return func(ao *AO) {
+		ao.itemSigner = s
+	}
+}
+
+// WithVariant sets the Variant tag added to the messages, spawns and dry runs that do not set one, so the client
+// can follow a new version of the ao network. The default is DefaultVariant.
+func WithVariant(variant string) Option {
+	return func(ao *AO) {
+		ao.mu.variant = variant
+		ao.cu.variant = variant
+	}
+}
+
+// WithoutSDKTags stops the client from tagging the data items it signs with SDK and SDK-Version, which
+// otherwise identify this SDK and its Version to gateways unless the caller sets those tags itself.
+func WithoutSDKTags() Option {
+	return func(ao *AO) {
+		ao.mu.noSDKTags = true
+	}
+}
+
+// WithResultCache caches up to size successful results of LoadResult and DryRun, and their variants, for ttl,
+// evicting the least recently used one when full, so repeated reads skip the CU. Results are keyed by their
+// request: the process, message and query parameters of a read, or the serialized message of a dry run. Errors,
+// results reporting an Error and results without output, which may not be ready yet, are never cached, and a
+// zero ttl keeps results until they are evicted. Cached results are shared between callers and must not be
+// modified. Use BypassCache for a call that needs fresh data.
+func WithResultCache(size int, ttl time.Duration) Option {
+	return func(ao *AO) {
+		ao.cache = newResultCache(size, ttl)
+	}
+}
+
+// WithDryRunCache caches successful dry runs for ttl, keyed by the serialized message, so dashboards polling
+// the same Info or Balance dry runs reach the CU once per ttl. Dry runs only read state, so a ttl of a second or
+// two is safe. It keeps the most recently used dry runs, up to 1024, and takes the place of WithResultCache for
+// dry runs. Use BypassCache for a dry run that must be fresh.
+func WithDryRunCache(ttl time.Duration) Option {
+	return func(ao *AO) {
+		ao.dryRuns = newResultCache(dryRunCacheSize, ttl)
+	}
+}
+
+// WithConcurrency limits how many requests batch calls such as SendMessages keep in flight.
+func WithConcurrency(n int) Option {
+	return func(ao *AO) {
+		ao.concurrency = n
+	}
+}
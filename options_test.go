@@ -0,0 +1,84 @@
+package aogo
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("Defaults", func(t *testing.T) {
+		ao, err := New()
+		assert.NoError(t, err)
+		assert.Equal(t, CuUrl, ao.cu.url)
+		assert.Equal(t, MuUrl, ao.mu.url)
+		assert.Equal(t, SuUrl, ao.su.url)
+		assert.Equal(t, GATEWAY, ao.gateway.url)
+		assert.Same(t, http.DefaultClient, ao.cu.client)
+	})
+
+	t.Run("Options", func(t *testing.T) {
+		ao, err := New(WithCUURL("http://cu"), WithMUURL("http://mu"), WithSUURL("http://su"), WithGatewayURL("http://gateway"), WithTimeout(time.Second))
+		assert.NoError(t, err)
+		assert.Equal(t, "http://cu", ao.cu.url)
+		assert.Equal(t, "http://mu", ao.mu.url)
+		assert.Equal(t, "http://su", ao.su.url)
+		assert.Equal(t, "http://gateway", ao.gateway.url)
+		assert.Equal(t, time.Second, ao.cu.timeout)
+		assert.Equal(t, time.Second, ao.mu.timeout)
+	})
+
+	t.Run("Deprecated", func(t *testing.T) {
+		ao, err := New(WthCU("http://cu"), WthMU("http://mu"))
+		assert.NoError(t, err)
+		assert.Equal(t, "http://cu", ao.cu.url)
+		assert.Equal(t, "http://mu", ao.mu.url)
+	})
+}
+
+func TestWithHTTPClient(t *testing.T) {
+	t.Run("Custom", func(t *testing.T) {
+		client := &http.Client{Transport: &http.Transport{MaxIdleConnsPerHost: 32}}
+		ao, err := New(WithHTTPClient(client), WithCUURL("http://cu"), WithMUURL("http://mu"))
+		assert.NoError(t, err)
+		assert.Same(t, client, ao.cu.client)
+		assert.Same(t, client, ao.mu.client)
+		assert.Equal(t, "http://cu", ao.cu.url)
+		assert.Equal(t, "http://mu", ao.mu.url)
+	})
+
+	t.Run("Nil", func(t *testing.T) {
+		ao, err := New(WithHTTPClient(nil))
+		assert.NoError(t, err)
+		assert.Same(t, http.DefaultClient, ao.cu.client)
+		assert.Same(t, http.DefaultClient, ao.mu.client)
+	})
+}
+
+func TestWithNetwork(t *testing.T) {
+	t.Run("Legacynet", func(t *testing.T) {
+		ao, err := New(WithNetwork(Network{CUURL: "http://cu", MUURL: "http://mu", SUURL: "http://su"}), WithNetwork(Legacynet))
+		assert.NoError(t, err)
+		assert.Equal(t, CuUrl, ao.cu.url)
+		assert.Equal(t, MuUrl, ao.mu.url)
+		assert.Equal(t, SuUrl, ao.su.url)
+	})
+
+	t.Run("Override", func(t *testing.T) {
+		ao, err := New(WithCUURLs([]string{"http://a", "http://b"}), WithNetwork(Legacynet), WithMUURL("http://mu"))
+		assert.NoError(t, err)
+		assert.Equal(t, Legacynet.CUURL, ao.cu.url)
+		assert.Empty(t, ao.cu.urls)
+		assert.Equal(t, "http://mu", ao.mu.url)
+	})
+
+	t.Run("Partial", func(t *testing.T) {
+		ao, err := New(WithGatewayURL("http://gateway"), WithNetwork(Network{CUURL: "http://cu"}))
+		assert.NoError(t, err)
+		assert.Equal(t, "http://cu", ao.cu.url)
+		assert.Equal(t, MuUrl, ao.mu.url)
+		assert.Equal(t, "http://gateway", ao.gateway.url)
+	})
+}
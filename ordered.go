@@ -0,0 +1,72 @@
+package aogo
+
+import (
+	"context"
+	"sync"
+)
+
+// sendQueues serializes sends per process, see WithOrderedSends. A nil *sendQueues lets every send through.
+type sendQueues struct {
+	mu     sync.Mutex
+	queues map[string]*sendQueue
+}
+
+// sendQueue holds the callers waiting for the send in flight to a process, in call order.
+type sendQueue struct {
+	waiting []chan struct{}
+}
+
+func newSendQueues() *sendQueues {
+	return &sendQueues{queues: make(map[string]*sendQueue)}
+}
+
+// acquire waits until every earlier send to process has finished and returns the function that lets the next
+// one through. Callers are let through in the order they called acquire. A caller that gives up because ctx is
+// done leaves the queue without holding up the others.
+func (q *sendQueues) acquire(ctx context.Context, process string) (release func(), err error) {
+	if q == nil {
+		return func() {}, nil
+	}
+	q.mu.Lock()
+	queue, busy := q.queues[process]
+	if !busy {
+		q.queues[process] = &sendQueue{}
+		q.mu.Unlock()
+		return func() { q.release(process) }, nil
+	}
+	turn := make(chan struct{})
+	queue.waiting = append(queue.waiting, turn)
+	q.mu.Unlock()
+
+	select {
+	case <-turn:
+		return func() { q.release(process) }, nil
+	case <-ctx.Done():
+	}
+	q.mu.Lock()
+	for i, c := range queue.waiting {
+		if c == turn {
+			queue.waiting = append(queue.waiting[:i], queue.waiting[i+1:]...)
+			q.mu.Unlock()
+			return nil, ctx.Err()
+		}
+	}
+	q.mu.Unlock()
+	// The turn was handed over while ctx was done, so it is passed on.
+	q.release(process)
+	return nil, ctx.Err()
+}
+
+// release hands the turn of process to the longest waiting caller, if any.
+func (q *sendQueues) release(process string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	queue := q.queues[process]
+	if len(queue.waiting) == 0 {
+		delete(q.queues, process)
+		return
+	}
+	next := queue.waiting[0]
+	queue.waiting = queue.waiting[1:]
+	close(next)
+}
@@ -0,0 +1,72 @@
+package aogo
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithOrderedSends(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	inFlight, maxInFlight := 0, 0
+	unblock := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		item, err := DecodeDataItem(b)
+		assert.NoError(t, err)
+		if item.Target == testProcess {
+			mu.Lock()
+			order = append(order, string(item.Data))
+			inFlight++
+			maxInFlight = max(maxInFlight, inFlight)
+			mu.Unlock()
+			if string(item.Data) == "0" {
+				<-unblock
+			}
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+		}
+		_, err = w.Write([]byte(`{"id": "` + item.ID + `"}`))
+		assert.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	ao, err := New(WithMUURL(srv.URL), WithOrderedSends(), WithSigner(setupSigner(t)))
+	assert.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := ao.SendMessage(testProcess, strconv.Itoa(i), nil, "", nil)
+			assert.NoError(t, err)
+		}()
+		// Give each send time to queue up before the next one is made.
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	// The first message to testProcess is still in flight, which does not hold up other processes.
+	_, err = ao.SendMessage(testModule, "other", nil, "", nil)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = ao.SendMessageContext(ctx, testProcess, "late", nil, "", nil)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	close(unblock)
+	wg.Wait()
+	assert.Equal(t, []string{"0", "1", "2", "3", "4"}, order)
+	assert.Equal(t, 1, maxInFlight)
+}
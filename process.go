@@ -0,0 +1,33 @@
+package aogo
+
+import (
+	"github.com/liteseed/goar/signer"
+	"github.com/liteseed/goar/tag"
+)
+
+// Process is a handle bound to a single process. It shares the HTTP client and configuration of the AO it came from.
+type Process struct {
+	ao *AO
+	ID string
+}
+
+// Process returns a handle for the process id.
+func (ao *AO) Process(id string) *Process {
+	return &Process{ao: ao, ID: id}
+}
+
+// Send signs and sends a message with data and tags to the process.
+func (p *Process) Send(data string, tags []tag.Tag, s *signer.Signer) (string, error) {
+	t := append([]tag.Tag{}, tags...)
+	return p.ao.SendMessage(p.ID, data, &t, "", s)
+}
+
+// DryRun evaluates action on the process without persisting it.
+func (p *Process) DryRun(action string, tags []tag.Tag) (*Response, error) {
+	return p.ao.DryRunAs(p.ID, "", action, tags)
+}
+
+// Result loads the result of message on the process.
+func (p *Process) Result(message string) (*Response, error) {
+	return p.ao.LoadResult(p.ID, message)
+}
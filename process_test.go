@@ -0,0 +1,54 @@
+package aogo
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/liteseed/goar/signer"
+	"github.com/liteseed/goar/tag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProcess(t *testing.T) {
+	process := testProcess
+	cuServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, process, r.URL.Query().Get("process-id"))
+		if r.Method == http.MethodPost {
+			var m Message
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&m))
+			assert.Equal(t, process, m.Target)
+			assert.Contains(t, *m.Tags, tag.Tag{Name: "Action", Value: "Info"})
+		} else {
+			assert.Equal(t, "/result/"+testMessage, r.URL.Path)
+		}
+		_, err := w.Write([]byte(`{"Messages": [], "Spawns": [], "Outputs": [], "Error": "", "GasUsed": 0}`))
+		assert.NoError(t, err)
+	}))
+	defer cuServer.Close()
+	muServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"id": "mockMessageID"}`))
+		assert.NoError(t, err)
+	}))
+	defer muServer.Close()
+
+	ao, err := New(WithCUURL(cuServer.URL), WithMUURL(muServer.URL))
+	assert.NoError(t, err)
+	p := ao.Process(process)
+	assert.Equal(t, process, p.ID)
+
+	s, err := signer.FromPath("./keys/wallet.json")
+	assert.NoError(t, err)
+	tags := []tag.Tag{{Name: "Action", Value: "Eval"}}
+	id, err := p.Send("1 + 1", tags, s)
+	assert.NoError(t, err)
+	assert.Equal(t, "mockMessageID", id)
+	assert.Len(t, tags, 1)
+
+	_, err = p.DryRun("Info", nil)
+	assert.NoError(t, err)
+
+	_, err = p.Result(testMessage)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,53 @@
+package aogo
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+)
+
+// ProgressFunc is told how many of the total bytes of a request body have been sent.
+type ProgressFunc func(bytesSent int64, total int64)
+
+type progressKey struct{}
+
+// WithUploadProgress returns a context that makes the MU calls given it, such as SendMessageContext,
+// SpawnProcessContext and SubmitDataItemContext, report the upload of their data item to progress as the body
+// is written, for example to drive a progress bar. total is the size of the signed data item, or of its gzipped
+// form with WithCompression. A retried request starts again from zero. UploadDataContext and
+// ResumeUploadContext report instead the bytes of data in the chunks the gateway has accepted. progress is
+// called from the goroutine writing the request and must not block.
+func WithUploadProgress(ctx context.Context, progress ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, progress)
+}
+
+// withProgress wraps the body of req, which holds raw, to report its upload to the ProgressFunc of ctx, if any.
+func withProgress(ctx context.Context, req *http.Request, raw []byte) {
+	progress, ok := ctx.Value(progressKey{}).(ProgressFunc)
+	if !ok || progress == nil {
+		return
+	}
+	total := int64(len(raw))
+	req.Body = &progressReader{ReadCloser: req.Body, total: total, progress: progress}
+	req.GetBody = func() (io.ReadCloser, error) {
+		return &progressReader{ReadCloser: io.NopCloser(bytes.NewReader(raw)), total: total, progress: progress}, nil
+	}
+}
+
+// progressReader reports the bytes read through it.
+type progressReader struct {
+	io.ReadCloser
+	sent     int64
+	total    int64
+	progress ProgressFunc
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		r.sent += int64(n)
+		r.progress(r.sent, r.total)
+	}
+	return n, err
+}
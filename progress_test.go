@@ -0,0 +1,77 @@
+package aogo
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithUploadProgress(t *testing.T) {
+	var contentLength int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentLength = r.ContentLength
+		body := r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			zr, err := gzip.NewReader(r.Body)
+			assert.NoError(t, err)
+			body = zr
+		}
+		b, err := io.ReadAll(body)
+		assert.NoError(t, err)
+		id, err := DataItemID(b)
+		assert.NoError(t, err)
+		_, err = w.Write([]byte(`{"id": "` + id + `"}`))
+		assert.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	ao, err := New(WithMUURL(srv.URL), WithSigner(setupSigner(t)))
+	assert.NoError(t, err)
+
+	var calls int
+	var sent, total int64
+	ctx := WithUploadProgress(context.Background(), func(bytesSent int64, size int64) {
+		assert.GreaterOrEqual(t, bytesSent, sent)
+		calls++
+		sent, total = bytesSent, size
+	})
+	_, err = ao.SendMessageContext(ctx, testProcess, strings.Repeat("a", 1<<20), nil, "", nil)
+	assert.NoError(t, err)
+	assert.Greater(t, calls, 1)
+	assert.Equal(t, total, sent)
+	assert.Equal(t, contentLength, total, "the body keeps its length")
+	assert.Greater(t, total, int64(1<<20))
+
+	calls = 0
+	_, err = ao.SendMessage(testProcess, "data", nil, "", nil)
+	assert.NoError(t, err)
+	assert.Zero(t, calls)
+
+	t.Run("Compressed", func(t *testing.T) {
+		ao, err := New(WithMUURL(srv.URL), WithSigner(setupSigner(t)), WithCompression())
+		assert.NoError(t, err)
+		random := make([]byte, 1<<20)
+		_, err = rand.Read(random)
+		assert.NoError(t, err)
+
+		calls, sent, total = 0, 0, 0
+		ctx := WithUploadProgress(context.Background(), func(bytesSent int64, size int64) {
+			assert.GreaterOrEqual(t, bytesSent, sent)
+			calls++
+			sent, total = bytesSent, size
+		})
+		_, err = ao.SendMessageContext(ctx, testProcess, base64.StdEncoding.EncodeToString(random), nil, "", nil)
+		assert.NoError(t, err)
+		assert.Greater(t, calls, 1)
+		assert.Equal(t, total, sent)
+		assert.Equal(t, contentLength, total, "progress counts the compressed body")
+	})
+}
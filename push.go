@@ -0,0 +1,101 @@
+package aogo
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/liteseed/goar/signer"
+	"github.com/liteseed/goar/tag"
+)
+
+var pushProtocolTags = map[string]bool{
+	"Data-Protocol": true, "Variant": true, "Type": true, "SDK": true, "SDK-Version": true,
+	"From-Process": true, "Pushed-For": true,
+}
+
+// PushResult continues the message flow of result, the result of message on process, by sending each of its
+// outbound messages to its target through the MU, signed by s, which stands in for the pushing MU of a full AO
+// deployment. Pushed messages are tagged From-Process and Pushed-For like the ones a MU pushes; processes only
+// take From-Process as the sender of messages signed by an authority they trust, so for the others From is the
+// address of s.
+//
+// Messages are sent one after the other in the order the process produced them, keeping their data and tags
+// other than the protocol tags, which are set anew. Each keeps its anchor, or gets one derived from process,
+// message and its position when it has none, so every push of a message carries the same anchor. Before
+// sending, the gateway is asked for the messages already pushed for message, and those found are reported
+// with their ID instead of being sent again; with WithIdempotentSends the SU is also asked before a failed
+// send is retried. A message pushed again before the gateway has indexed the first push is still sent twice.
+//
+// The returned results are aligned with result.Messages; a failed push does not stop the rest, and result is
+// never modified, so a caller can retry only the entries whose Err is set, or the whole result.
+func (ao *AO) PushResult(process string, message string, result *Response, s *signer.Signer) []SendResult {
+	if result == nil {
+		return nil
+	}
+	results := make([]SendResult, len(result.Messages))
+	fail := func(err error) []SendResult {
+		for i := range results {
+			results[i].Err = err
+		}
+		return results
+	}
+	if err := validateID("process", process); err != nil {
+		return fail(err)
+	}
+	if err := validateID("message", message); err != nil {
+		return fail(err)
+	}
+	pushed, err := ao.gateway.pushedMessages(process, []string{message})
+	if err != nil {
+		return fail(fmt.Errorf("look up earlier pushes: %w", err))
+	}
+
+	for i, m := range result.Messages {
+		if m.Target == "" {
+			results[i] = SendResult{Err: errors.New("outbound message has no target")}
+			continue
+		}
+		anchor := m.Anchor
+		if len(anchor) != 32 {
+			// Data item anchors are exactly 32 bytes; anything else cannot be carried over.
+			anchor = pushAnchor(process, message, i)
+		}
+		if id := pushedID(pushed, m.Target, anchor); id != "" {
+			results[i] = SendResult{ID: id}
+			continue
+		}
+		tags := make([]tag.Tag, 0, len(m.Tags)+2)
+		for _, t := range m.Tags {
+			// sendMessage sets the protocol tags itself.
+			if !pushProtocolTags[t.Name] {
+				tags = append(tags, t)
+			}
+		}
+		tags = append(tags, tag.Tag{Name: "From-Process", Value: process}, tag.Tag{Name: "Pushed-For", Value: message})
+		id, err := ao.sendMessage(context.Background(), m.Target, []byte(m.Data), &tags, anchor, s)
+		results[i] = SendResult{ID: id, Err: err}
+	}
+	return results
+}
+
+// pushAnchor returns the anchor of the outbound message at index of the result of message on process.
+func pushAnchor(process string, message string, index int) string {
+	sum := sha256.Sum256([]byte(process + "/" + message + "/" + strconv.Itoa(index)))
+	return base64.RawURLEncoding.EncodeToString(sum[:])[:32]
+}
+
+// pushedID returns the ID of the message in pushed sent to target with anchor, or "" if there is none. The
+// gateway may report anchors as they are or base64url encoded.
+func pushedID(pushed []Transaction, target string, anchor string) string {
+	encoded := base64.RawURLEncoding.EncodeToString([]byte(anchor))
+	for _, tx := range pushed {
+		if tx.Recipient == target && (tx.Anchor == anchor || tx.Anchor == encoded) {
+			return tx.ID
+		}
+	}
+	return ""
+}
@@ -0,0 +1,124 @@
+package aogo
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/liteseed/goar/signer"
+	"github.com/liteseed/goar/tag"
+	"github.com/liteseed/goar/transaction/data_item"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPushResult(t *testing.T) {
+	var items []*data_item.DataItem
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		item, err := data_item.Decode(b)
+		assert.NoError(t, err)
+		items = append(items, item)
+		data, err := base64.RawURLEncoding.DecodeString(item.Data)
+		assert.NoError(t, err)
+		if string(data) == "fail" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, err = w.Write([]byte(`{"id": "` + string(data) + `"}`))
+		assert.NoError(t, err)
+	}))
+	defer srv.Close()
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req graphQLRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, []any{testModule}, req.Variables["process"])
+		assert.Equal(t, []any{testMessage}, req.Variables["pushedFor"])
+		_, err := w.Write([]byte(`{"data": {"transactions": {"edges": []}}}`))
+		assert.NoError(t, err)
+	}))
+	defer gateway.Close()
+
+	ao, err := New(WithMUURL(srv.URL), WithGatewayURL(gateway.URL))
+	assert.NoError(t, err)
+	s, err := signer.FromPath("./keys/wallet.json")
+	assert.NoError(t, err)
+
+	anchor := "00000000000000000000000000000007"
+	result := &Response{Messages: []ResultMessage{
+		{Target: testProcess, Anchor: anchor, Data: "a", Tags: []tag.Tag{
+			{Name: "Data-Protocol", Value: "ao"},
+			{Name: "Type", Value: "Message"},
+			{Name: "Action", Value: "Credit-Notice"},
+		}},
+		{Target: testProcess, Data: "fail"},
+		{Data: "no target"},
+		{Target: testMessage, Anchor: "short", Data: "c"},
+	}}
+	results := ao.PushResult(testModule, testMessage, result, s)
+	assert.Len(t, results, 4)
+	assert.Equal(t, "a", results[0].ID)
+	assert.NoError(t, results[0].Err)
+	assert.Error(t, results[1].Err)
+	assert.ErrorContains(t, results[2].Err, "no target")
+	assert.Equal(t, "c", results[3].ID)
+	assert.NoError(t, results[3].Err)
+
+	// Messages are pushed in order, to their own targets, and the invalid one is never sent.
+	assert.Len(t, items, 3)
+	assert.Equal(t, testProcess, items[0].Target)
+	assert.Equal(t, anchor, items[0].Anchor)
+	assert.Equal(t, testMessage, items[2].Target)
+	assert.Equal(t, pushAnchor(testModule, testMessage, 3), items[2].Anchor)
+
+	tags := map[string]int{}
+	for _, tg := range *items[0].Tags {
+		tags[tg.Name]++
+	}
+	assert.Equal(t, 1, tags["Data-Protocol"])
+	assert.Equal(t, 1, tags["Type"])
+	assert.Equal(t, 1, tags["Action"])
+	assert.Contains(t, *items[0].Tags, tag.Tag{Name: "From-Process", Value: testModule})
+	assert.Contains(t, *items[0].Tags, tag.Tag{Name: "Pushed-For", Value: testMessage})
+	assert.Len(t, result.Messages[0].Tags, 3)
+
+	assert.Nil(t, ao.PushResult(testModule, testMessage, nil, s))
+}
+
+func TestPushResultAgain(t *testing.T) {
+	sent := 0
+	mu := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent++
+		b, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		id, err := DataItemID(b)
+		assert.NoError(t, err)
+		_, err = w.Write([]byte(`{"id": "` + id + `"}`))
+		assert.NoError(t, err)
+	}))
+	defer mu.Close()
+	// The gateway has indexed the first message pushed earlier, with its anchor base64url encoded.
+	anchor := base64.RawURLEncoding.EncodeToString([]byte(pushAnchor(testModule, testMessage, 0)))
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"data": {"transactions": {"edges": [{"node": {"id": "` + testModule + `", "recipient": "` +
+			testProcess + `", "anchor": "` + anchor + `"}}]}}}`))
+		assert.NoError(t, err)
+	}))
+	defer gateway.Close()
+
+	ao, err := New(WithMUURL(mu.URL), WithGatewayURL(gateway.URL), WithSigner(setupSigner(t)))
+	assert.NoError(t, err)
+	result := &Response{Messages: []ResultMessage{{Target: testProcess, Data: "a"}, {Target: testProcess, Data: "b"}}}
+	results := ao.PushResult(testModule, testMessage, result, nil)
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, testModule, results[0].ID, "the earlier push is reported")
+	assert.NoError(t, results[1].Err)
+	assert.Equal(t, 1, sent, "only the message not pushed yet is sent")
+
+	results = ao.PushResult("bad", testMessage, result, nil)
+	assert.ErrorIs(t, results[0].Err, ErrInvalidID)
+	assert.ErrorIs(t, results[1].Err, ErrInvalidID)
+}
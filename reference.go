@@ -0,0 +1,34 @@
+package aogo
+
+import (
+	"strconv"
+	"sync"
+)
+
+// references hands out Reference tag values per process, see WithAutoReference. It is safe for concurrent use,
+// and a nil *references hands out none.
+type references struct {
+	start uint64
+
+	mu   sync.Mutex
+	next map[string]uint64
+}
+
+func newReferences(start uint64) *references {
+	return &references{start: start, next: make(map[string]uint64)}
+}
+
+// take returns the next reference of process, or "" if automatic references are disabled.
+func (r *references) take(process string) string {
+	if r == nil {
+		return ""
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n, ok := r.next[process]
+	if !ok {
+		n = r.start
+	}
+	r.next[process] = n + 1
+	return strconv.FormatUint(n, 10)
+}
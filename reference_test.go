@@ -0,0 +1,80 @@
+package aogo
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/liteseed/goar/signer"
+	"github.com/liteseed/goar/tag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithAutoReference(t *testing.T) {
+	var sent []string
+	srv := setupMU(t, func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		item, err := DecodeDataItem(b)
+		assert.NoError(t, err)
+		reference, _ := FindTag(item.Tags, "Reference")
+		sent = append(sent, item.Target[:4]+":"+reference)
+		_, err = w.Write([]byte(`{"id": "` + item.ID + `"}`))
+		assert.NoError(t, err)
+	})
+
+	ao, err := New(WithMUURL(srv.URL), WithAutoReference(7), WithSigner(setupSigner(t)))
+	assert.NoError(t, err)
+
+	for _, process := range []string{testProcess, testProcess, testModule} {
+		_, err := ao.SendMessage(process, "data", nil, "", nil)
+		assert.NoError(t, err)
+	}
+	res, err := ao.SendMessageResult(testProcess, "data", nil, "", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "9", res.Reference)
+
+	tags := []tag.Tag{{Name: "Reference", Value: "custom"}}
+	res, err = ao.SendMessageResult(testProcess, "data", &tags, "", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "custom", res.Reference)
+
+	res, err = ao.SendMessageResult(testProcess, "data", nil, "", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "10", res.Reference, "caller references do not use up the counter")
+
+	assert.Equal(t, []string{"yugM:7", "yugM:8", "SBNb:7", "yugM:9", "yugM:custom", "yugM:10"}, sent)
+
+	t.Run("Disabled", func(t *testing.T) {
+		sent = nil
+		ao, err := New(WithMUURL(srv.URL), WithSigner(setupSigner(t)))
+		assert.NoError(t, err)
+		res, err := ao.SendMessageResult(testProcess, "data", nil, "", nil)
+		assert.NoError(t, err)
+		assert.Empty(t, res.Reference)
+		assert.Equal(t, []string{"yugM:"}, sent)
+	})
+
+	t.Run("MessengerUnit", func(t *testing.T) {
+		mu := &taggingMU{}
+		ao, err := NewWithUnits(nil, mu, WithAutoReference(1))
+		assert.NoError(t, err)
+		shared := []tag.Tag{{Name: "Action", Value: "Eval"}}
+		res, err := ao.SendMessageResult(testProcess, "data", &shared, "", nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "1", res.Reference)
+		assert.Equal(t, []tag.Tag{{Name: "Action", Value: "Eval"}, {Name: "Reference", Value: "1"}}, mu.tags)
+		assert.Len(t, shared, 1, "the caller's tags are left as they were")
+	})
+}
+
+// taggingMU is a MessengerUnit that records the tags of the last message.
+type taggingMU struct {
+	stubMU
+	tags []tag.Tag
+}
+
+func (m *taggingMU) SendMessage(process string, data string, tags *[]tag.Tag, anchor string, s *signer.Signer) (string, error) {
+	m.tags = *tags
+	return "message", nil
+}
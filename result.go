@@ -0,0 +1,224 @@
+package aogo
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/liteseed/goar/tag"
+)
+
+// ResultMessage is an outbound message produced by a process evaluation.
+type ResultMessage struct {
+	Target string    `json:"Target"`
+	Anchor string    `json:"Anchor"`
+	Tags   []tag.Tag `json:"Tags"`
+	// Data is the message payload. Non-string payloads are kept as their JSON encoding.
+	Data string `json:"Data"`
+}
+
+func (m *ResultMessage) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		Target string          `json:"Target"`
+		Anchor string          `json:"Anchor"`
+		Tags   json.RawMessage `json:"Tags"`
+		Data   json.RawMessage `json:"Data"`
+	}
+	err := json.Unmarshal(b, &raw)
+	if err != nil {
+		return err
+	}
+	tags, err := decodeTags(raw.Tags)
+	if err != nil {
+		return err
+	}
+	*m = ResultMessage{Target: raw.Target, Anchor: raw.Anchor, Tags: tags, Data: decodeData(raw.Data)}
+	return nil
+}
+
+// Tag returns the value of the tag called name. The boolean reports whether the tag is present.
+func (m ResultMessage) Tag(name string) (string, bool) {
+	return FindTag(m.Tags, name)
+}
+
+// TagFold is like Tag but compares names case-insensitively.
+func (m ResultMessage) TagFold(name string) (string, bool) {
+	return FindTagFold(m.Tags, name)
+}
+
+// OutboundTo returns the messages of r addressed to target.
+func (r *Response) OutboundTo(target string) []ResultMessage {
+	var messages []ResultMessage
+	for _, m := range r.Messages {
+		if m.Target == target {
+			messages = append(messages, m)
+		}
+	}
+	return messages
+}
+
+// MessageByReference returns the first message of r that replies to the message whose Reference tag is ref, that
+// is whose X-Reference tag is ref, as set by msg.reply in AO processes. Messages without an X-Reference tag
+// never match, and neither does an empty ref. See WithAutoReference for numbering the messages sent.
+func (r *Response) MessageByReference(ref string) (*ResultMessage, bool) {
+	if ref == "" {
+		return nil, false
+	}
+	for i, m := range r.Messages {
+		if v, ok := m.Tag("X-Reference"); ok && v == ref {
+			return &r.Messages[i], true
+		}
+	}
+	return nil, false
+}
+
+// ConsoleOutput joins the printable data of r.Outputs in order, one output per line. Outputs may be plain
+// strings or objects whose data is either a string or an object with an output field, as written by aos.
+func (r *Response) ConsoleOutput() string {
+	lines := make([]string, 0, len(r.Outputs))
+	for _, o := range r.Outputs {
+		if line, ok := printable(o); ok {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func printable(o any) (string, bool) {
+	switch v := o.(type) {
+	case nil:
+		return "", false
+	case string:
+		return v, true
+	case map[string]any:
+		data, ok := v["data"]
+		if !ok {
+			return stringify(v), true
+		}
+		if d, ok := data.(map[string]any); ok {
+			if out, ok := d["output"]; ok {
+				return printable(out)
+			}
+		}
+		return printable(data)
+	}
+	return stringify(o), true
+}
+
+// decodeTags accepts the CU's list of {name, value} objects as well as a plain name to value object.
+func decodeTags(b json.RawMessage) ([]tag.Tag, error) {
+	if len(b) == 0 || string(b) == "null" {
+		return nil, nil
+	}
+
+	var list []map[string]any
+	if err := json.Unmarshal(b, &list); err == nil {
+		tags := make([]tag.Tag, 0, len(list))
+		for _, t := range list {
+			tags = append(tags, tag.Tag{Name: tagField(t, "name", "Name"), Value: tagField(t, "value", "Value")})
+		}
+		return tags, nil
+	}
+
+	var object map[string]any
+	if err := json.Unmarshal(b, &object); err != nil {
+		return nil, fmt.Errorf("unsupported tags format: %s", b)
+	}
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	tags := make([]tag.Tag, 0, len(object))
+	for _, name := range names {
+		tags = append(tags, tag.Tag{Name: name, Value: stringify(object[name])})
+	}
+	return tags, nil
+}
+
+func tagField(t map[string]any, keys ...string) string {
+	for _, k := range keys {
+		if v, ok := t[k]; ok {
+			return stringify(v)
+		}
+	}
+	return ""
+}
+
+// decodeData returns string payloads as is and any other JSON value as its encoding.
+func decodeData(b json.RawMessage) string {
+	if len(b) == 0 || string(b) == "null" {
+		return ""
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		return s
+	}
+	return string(b)
+}
+
+func stringify(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+// Hash returns the hex SHA-256 of the Messages, Spawns, Outputs, Error and GasUsed of r, computed over a
+// canonical encoding so results with the same content hash the same however the CU laid them out: object keys
+// are sorted, message data holding a JSON object or array is compacted with sorted keys and missing lists count
+// as empty. It suits consensus checks and cache keys. It returns "" if r holds a value JSON cannot encode.
+func (r *Response) Hash() string {
+	canonical := struct {
+		Messages []ResultMessage
+		Spawns   []any
+		Outputs  []any
+		Error    string
+		GasUsed  Gas
+	}{
+		Messages: make([]ResultMessage, len(r.Messages)),
+		Spawns:   emptyIfNil(r.Spawns),
+		Outputs:  emptyIfNil(r.Outputs),
+		Error:    r.Error,
+		GasUsed:  r.GasUsed,
+	}
+	for i, m := range r.Messages {
+		m.Tags = append([]tag.Tag{}, m.Tags...)
+		m.Data = canonicalData(m.Data)
+		canonical.Messages[i] = m
+	}
+	b, err := json.Marshal(canonical)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func emptyIfNil(v []any) []any {
+	if v == nil {
+		return []any{}
+	}
+	return v
+}
+
+// canonicalData re-encodes data holding a JSON object or array with sorted keys and no insignificant space.
+func canonicalData(data string) string {
+	trimmed := bytes.TrimSpace([]byte(data))
+	if len(trimmed) == 0 || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return data
+	}
+	// Numbers are kept as written so large integers survive.
+	d := json.NewDecoder(bytes.NewReader(trimmed))
+	d.UseNumber()
+	var v any
+	if err := d.Decode(&v); err != nil || d.More() {
+		return data
+	}
+	return stringify(v)
+}
@@ -0,0 +1,125 @@
+package aogo
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/liteseed/goar/tag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResultMessageUnmarshal(t *testing.T) {
+	t.Run("TagList", func(t *testing.T) {
+		var m ResultMessage
+		err := json.Unmarshal([]byte(`{"Target": "t", "Anchor": "a", "Data": "hello", "Tags": [{"name": "Action", "value": "Credit-Notice"}, {"Name": "Quantity", "Value": 10}]}`), &m)
+		assert.NoError(t, err)
+		assert.Equal(t, "t", m.Target)
+		assert.Equal(t, "a", m.Anchor)
+		assert.Equal(t, "hello", m.Data)
+		assert.Equal(t, []tag.Tag{{Name: "Action", Value: "Credit-Notice"}, {Name: "Quantity", Value: "10"}}, m.Tags)
+	})
+
+	t.Run("TagObject", func(t *testing.T) {
+		var m ResultMessage
+		err := json.Unmarshal([]byte(`{"Target": "t", "Tags": {"b": "2", "a": "1"}}`), &m)
+		assert.NoError(t, err)
+		assert.Equal(t, []tag.Tag{{Name: "a", Value: "1"}, {Name: "b", Value: "2"}}, m.Tags)
+	})
+
+	t.Run("ObjectData", func(t *testing.T) {
+		var m ResultMessage
+		err := json.Unmarshal([]byte(`{"Target": "t", "Data": {"balance": "100"}}`), &m)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"balance": "100"}`, m.Data)
+		assert.Nil(t, m.Tags)
+	})
+
+	t.Run("InvalidTags", func(t *testing.T) {
+		var m ResultMessage
+		err := json.Unmarshal([]byte(`{"Tags": "nope"}`), &m)
+		assert.Error(t, err)
+	})
+}
+
+func TestOutboundTo(t *testing.T) {
+	var res Response
+	err := json.Unmarshal([]byte(`{"Messages": [{"Target": "a", "Data": "1"}, {"Target": "b", "Data": "2"}, {"Target": "a", "Data": "3"}]}`), &res)
+	assert.NoError(t, err)
+
+	messages := res.OutboundTo("a")
+	assert.Len(t, messages, 2)
+	assert.Equal(t, "1", messages[0].Data)
+	assert.Equal(t, "3", messages[1].Data)
+	assert.Empty(t, res.OutboundTo("c"))
+}
+
+func TestMessageByReference(t *testing.T) {
+	res := &Response{Messages: []ResultMessage{
+		{Target: "a", Data: "no tags"},
+		{Target: "a", Tags: []tag.Tag{{Name: "Reference", Value: "3"}}, Data: "own reference"},
+		{Target: "b", Tags: []tag.Tag{{Name: "X-Reference", Value: "3"}}, Data: "reply"},
+	}}
+
+	m, ok := res.MessageByReference("3")
+	assert.True(t, ok)
+	assert.Equal(t, "reply", m.Data)
+	assert.Same(t, &res.Messages[2], m)
+
+	_, ok = res.MessageByReference("4")
+	assert.False(t, ok)
+	_, ok = res.MessageByReference("")
+	assert.False(t, ok)
+	_, ok = (&Response{}).MessageByReference("3")
+	assert.False(t, ok)
+}
+
+func TestResultMessageTag(t *testing.T) {
+	m := ResultMessage{Tags: []tag.Tag{{Name: "Error", Value: "Insufficient Balance"}}}
+
+	v, ok := m.Tag("Error")
+	assert.True(t, ok)
+	assert.Equal(t, "Insufficient Balance", v)
+
+	_, ok = m.Tag("error")
+	assert.False(t, ok)
+
+	v, ok = m.TagFold("error")
+	assert.True(t, ok)
+	assert.Equal(t, "Insufficient Balance", v)
+}
+
+func TestConsoleOutput(t *testing.T) {
+	var r Response
+	err := json.Unmarshal([]byte(`{"Outputs": [
+		"plain",
+		{"data": "hello", "print": true},
+		{"data": {"output": "from aos", "prompt": "aos> "}},
+		{"data": {"json": "undefined", "output": 2}},
+		null
+	]}`), &r)
+	assert.NoError(t, err)
+	assert.Equal(t, "plain\nhello\nfrom aos\n2", r.ConsoleOutput())
+
+	assert.Equal(t, "", (&Response{}).ConsoleOutput())
+}
+
+func TestResponseHash(t *testing.T) {
+	decode := func(s string) *Response {
+		var r Response
+		assert.NoError(t, json.Unmarshal([]byte(s), &r))
+		return &r
+	}
+	a := decode(`{"Messages": [{"Target": "x", "Tags": [{"name": "Action", "value": "A"}], "Data": {"b": 1, "a": 12345678901234567890}}],
+		"Spawns": [], "Outputs": [{"data": "hi", "print": true}], "Error": "", "GasUsed": 10}`)
+	b := decode(`{"GasUsed": "10", "Outputs": [{"print": true, "data": "hi"}],
+		"Messages": [{"Data": {"a": 12345678901234567890, "b": 1}, "Tags": {"Action": "A"}, "Target": "x"}]}`)
+	c := decode(`{"Messages": [{"Target": "x", "Tags": [{"name": "Action", "value": "A"}], "Data": {"b": 1, "a": 12345678901234567891}}],
+		"Outputs": [{"data": "hi", "print": true}], "GasUsed": 10}`)
+
+	assert.Len(t, a.Hash(), 64)
+	assert.Equal(t, a.Hash(), b.Hash())
+	assert.NotEqual(t, a.Hash(), c.Hash())
+	assert.Equal(t, (&Response{}).Hash(), (&Response{Messages: []ResultMessage{}, Outputs: []any{}}).Hash())
+	assert.NotEqual(t, (&Response{}).Hash(), (&Response{Error: "boom"}).Hash())
+	assert.Equal(t, (&Response{Outputs: []any{func() {}}}).Hash(), "")
+}
@@ -0,0 +1,170 @@
+package aogo
+
+import (
+	"context"
+	"io"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetryPolicy controls how a unit retries failed requests.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first. Values below 2 disable retries.
+	MaxAttempts int
+	// BaseDelay is the backoff before the first retry. It doubles on every following attempt.
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff between two attempts. Zero leaves it uncapped.
+	MaxDelay time.Duration
+	// Backoff, if set, computes the backoff instead of BaseDelay and MaxDelay.
+	Backoff Backoff
+	// RetryIf, if set, decides which failed attempts are retried instead of the unit's default: transport errors
+	// and 502, 503 and 504 responses, plus 429 and every 5xx on the MU. resp is nil when err is set.
+	// Requests that are never retried, such as dry runs, are not affected.
+	RetryIf func(req *http.Request, resp *http.Response, err error) bool
+}
+
+// DefaultRetryPolicy is used by the CU and MU unless overridden with WithCURetry or WithMURetry.
+var DefaultRetryPolicy = RetryPolicy{MaxAttempts: 3, BaseDelay: 200 * time.Millisecond, MaxDelay: 5 * time.Second}
+
+// NoRetry sends every request exactly once.
+var NoRetry = RetryPolicy{MaxAttempts: 1}
+
+// Backoff computes how long to wait before retry number attempt, starting at 1 for the first retry.
+// Implementations are called from several goroutines at once.
+type Backoff interface {
+	NextDelay(attempt int) time.Duration
+}
+
+// BackoffFunc adapts a function to a Backoff.
+type BackoffFunc func(attempt int) time.Duration
+
+func (f BackoffFunc) NextDelay(attempt int) time.Duration {
+	return f(attempt)
+}
+
+// Constant waits d before every retry.
+func Constant(d time.Duration) Backoff {
+	return BackoffFunc(func(int) time.Duration { return d })
+}
+
+// ExponentialJitter doubles the backoff from base on every retry up to max, which zero leaves uncapped, and
+// picks each delay at random in the upper half so that clients do not retry in lockstep. It is the backoff of
+// a RetryPolicy without Backoff.
+func ExponentialJitter(base time.Duration, max time.Duration) Backoff {
+	return BackoffFunc(func(attempt int) time.Duration {
+		d := base
+		for i := 1; i < attempt && (max <= 0 || d < max) && d < math.MaxInt64/2; i++ {
+			d *= 2
+		}
+		if max > 0 && d > max {
+			d = max
+		}
+		if d <= 0 {
+			return 0
+		}
+		return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+	})
+}
+
+// delay returns the backoff before retry number attempt.
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	if p.Backoff != nil {
+		return p.Backoff.NextDelay(attempt)
+	}
+	return ExponentialJitter(p.BaseDelay, p.MaxDelay).NextDelay(attempt)
+}
+
+// do sends the request built by newRequest until it succeeds, retryable reports false or attempts run out.
+// p.RetryIf replaces retryable unless retryable is nil, which sends the request once.
+// A Retry-After header on a 429 or 503 response replaces the computed backoff. Waiting between attempts
+// stops as soon as ctx is done, and no wait is started that would outlast the ctx deadline.
+func (p RetryPolicy) do(ctx context.Context, send func(*http.Request) (*http.Response, error), newRequest func() (*http.Request, error), retryable func(*http.Response, error) bool) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		req, err := newRequest()
+		if err != nil {
+			return nil, err
+		}
+		req = withAttempt(req, attempt)
+		resp, err := send(req)
+		if attempt >= p.MaxAttempts || ctx.Err() != nil || !p.retryable(retryable, req, resp, err) {
+			return resp, err
+		}
+		delay := p.delay(attempt)
+		if d, ok := retryAfter(resp, time.Now()); ok {
+			delay = d
+		}
+		// Give up with the current outcome rather than sleep past the deadline.
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return resp, err
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// retryAfter parses the Retry-After header of a 429 or 503 response, in either its seconds or HTTP-date form.
+func retryAfter(resp *http.Response, now time.Time) (time.Duration, bool) {
+	if resp == nil || (resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable) {
+		return 0, false
+	}
+	v := strings.TrimSpace(resp.Header.Get("Retry-After"))
+	if v == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(v); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(v); err == nil {
+		return max(date.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// retryServerError retries transport failures, 429 and 5xx responses.
+func retryServerError(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+}
+
+// retryGatewayError retries transport failures and the 502, 503 and 504 responses seen while a unit restarts.
+func retryGatewayError(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryable reports whether the attempt of req should be retried according to p.RetryIf, or else the unit
+// default. A nil unit default means the request is sent once.
+func (p RetryPolicy) retryable(unitDefault func(*http.Response, error) bool, req *http.Request, resp *http.Response, err error) bool {
+	if unitDefault == nil {
+		return false
+	}
+	if p.RetryIf != nil {
+		return p.RetryIf(req, resp, err)
+	}
+	return unitDefault(resp, err)
+}
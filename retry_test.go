@@ -0,0 +1,341 @@
+package aogo
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/liteseed/goar/signer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 5, BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}
+	for attempt := 1; attempt <= 5; attempt++ {
+		d := p.delay(attempt)
+		assert.LessOrEqual(t, d, 300*time.Millisecond)
+		assert.GreaterOrEqual(t, d, 50*time.Millisecond)
+	}
+	assert.Equal(t, time.Duration(0), NoRetry.delay(1))
+
+	p.Backoff = Constant(time.Second)
+	assert.Equal(t, time.Second, p.delay(3))
+}
+
+func TestBackoff(t *testing.T) {
+	t.Run("Constant", func(t *testing.T) {
+		b := Constant(50 * time.Millisecond)
+		for attempt := 1; attempt <= 3; attempt++ {
+			assert.Equal(t, 50*time.Millisecond, b.NextDelay(attempt))
+		}
+	})
+
+	t.Run("ExponentialJitter", func(t *testing.T) {
+		b := ExponentialJitter(100*time.Millisecond, time.Second)
+		for attempt, upper := range []time.Duration{100, 200, 400, 800, 1000, 1000} {
+			d := b.NextDelay(attempt + 1)
+			assert.LessOrEqual(t, d, upper*time.Millisecond)
+			assert.GreaterOrEqual(t, d, upper*time.Millisecond/2)
+		}
+		assert.Positive(t, ExponentialJitter(time.Second, 0).NextDelay(100))
+		assert.Zero(t, ExponentialJitter(0, time.Second).NextDelay(1))
+	})
+
+	t.Run("WithBackoff", func(t *testing.T) {
+		var attempts []time.Time
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts = append(attempts, time.Now())
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer srv.Close()
+
+		var calls []int
+		backoff := BackoffFunc(func(attempt int) time.Duration {
+			calls = append(calls, attempt)
+			return 20 * time.Millisecond
+		})
+		ao, err := New(WithCUURL(srv.URL), WithCURetry(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Hour}), WithBackoff(backoff))
+		assert.NoError(t, err)
+		assert.NotNil(t, ao.mu.retry.Backoff)
+		_, err = ao.LoadResult(testProcess, testMessage)
+		assert.Error(t, err)
+		assert.Equal(t, []int{1, 2}, calls)
+		assert.Len(t, attempts, 3)
+		assert.GreaterOrEqual(t, attempts[2].Sub(attempts[1]), 20*time.Millisecond)
+	})
+}
+
+func TestMURetry(t *testing.T) {
+	s, err := signer.FromPath("./keys/wallet.json")
+	assert.NoError(t, err)
+
+	t.Run("RetriesServerErrors", func(t *testing.T) {
+		var attempts atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if attempts.Add(1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			_, err := w.Write([]byte(`{"id": "mockMessageID"}`))
+			assert.NoError(t, err)
+		}))
+		defer srv.Close()
+
+		ao, err := New(WithMUURL(srv.URL), WithMURetry(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}))
+		assert.NoError(t, err)
+
+		id, err := ao.SendMessage(testProcess, "data", nil, "", s)
+		assert.NoError(t, err)
+		assert.Equal(t, "mockMessageID", id)
+		assert.Equal(t, int32(3), attempts.Load())
+	})
+
+	t.Run("NoRetry", func(t *testing.T) {
+		var attempts atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts.Add(1)
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer srv.Close()
+
+		ao, err := New(WithMUURL(srv.URL), WithMURetry(NoRetry))
+		assert.NoError(t, err)
+
+		_, err = ao.SpawnProcess(testModule, nil, nil, s)
+		assert.Error(t, err)
+		assert.Equal(t, int32(1), attempts.Load())
+	})
+
+	t.Run("ClientErrorNotRetried", func(t *testing.T) {
+		var attempts atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts.Add(1)
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer srv.Close()
+
+		ao, err := New(WithMUURL(srv.URL), WithMURetry(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}))
+		assert.NoError(t, err)
+
+		_, err = ao.SendMessage(testProcess, "data", nil, "", s)
+		assert.Error(t, err)
+		assert.Equal(t, int32(1), attempts.Load())
+	})
+
+	t.Run("StopsBeforeDeadline", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer srv.Close()
+
+		ao, err := New(WithMUURL(srv.URL), WithMUTimeout(50*time.Millisecond), WithMURetry(RetryPolicy{MaxAttempts: 5, BaseDelay: 10 * time.Second}))
+		assert.NoError(t, err)
+
+		start := time.Now()
+		_, err = ao.SendMessage(testProcess, "data", nil, "", s)
+		var aoErr *AOError
+		assert.True(t, errors.As(err, &aoErr))
+		assert.Equal(t, http.StatusInternalServerError, aoErr.StatusCode)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+}
+
+func TestCURetry(t *testing.T) {
+	t.Run("RetriesGatewayErrors", func(t *testing.T) {
+		var attempts atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if attempts.Add(1) == 1 {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			_, err := w.Write([]byte(`{"Messages": [], "Spawns": [], "Outputs": [], "Error": "", "GasUsed": 0}`))
+			assert.NoError(t, err)
+		}))
+		defer srv.Close()
+
+		ao, err := New(WithCUURL(srv.URL), WithCURetry(RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}))
+		assert.NoError(t, err)
+
+		_, err = ao.LoadResult(testProcess, testMessage)
+		assert.NoError(t, err)
+		assert.Equal(t, int32(2), attempts.Load())
+	})
+
+	t.Run("SurfacesLastBody", func(t *testing.T) {
+		var attempts atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts.Add(1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, err := w.Write([]byte("cu is restarting"))
+			assert.NoError(t, err)
+		}))
+		defer srv.Close()
+
+		ao, err := New(WithCUURL(srv.URL), WithCURetry(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}))
+		assert.NoError(t, err)
+
+		_, err = ao.LoadResult(testProcess, testMessage)
+		assert.ErrorContains(t, err, "cu is restarting")
+		assert.Equal(t, int32(3), attempts.Load())
+	})
+
+	t.Run("InternalErrorNotRetried", func(t *testing.T) {
+		var attempts atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts.Add(1)
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer srv.Close()
+
+		ao, err := New(WithCUURL(srv.URL), WithCURetry(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}))
+		assert.NoError(t, err)
+
+		_, err = ao.LoadResult(testProcess, testMessage)
+		assert.Error(t, err)
+		assert.Equal(t, int32(1), attempts.Load())
+	})
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	response := func(status int, value string) *http.Response {
+		resp := &http.Response{StatusCode: status, Header: http.Header{}}
+		if value != "" {
+			resp.Header.Set("Retry-After", value)
+		}
+		return resp
+	}
+
+	d, ok := retryAfter(response(http.StatusTooManyRequests, "3"), now)
+	assert.True(t, ok)
+	assert.Equal(t, 3*time.Second, d)
+
+	d, ok = retryAfter(response(http.StatusServiceUnavailable, now.Add(90*time.Second).Format(http.TimeFormat)), now)
+	assert.True(t, ok)
+	assert.Equal(t, 90*time.Second, d)
+
+	d, ok = retryAfter(response(http.StatusServiceUnavailable, now.Add(-time.Minute).Format(http.TimeFormat)), now)
+	assert.True(t, ok)
+	assert.Equal(t, time.Duration(0), d)
+
+	_, ok = retryAfter(response(http.StatusServiceUnavailable, "soon"), now)
+	assert.False(t, ok)
+	_, ok = retryAfter(response(http.StatusServiceUnavailable, ""), now)
+	assert.False(t, ok)
+	_, ok = retryAfter(response(http.StatusInternalServerError, "3"), now)
+	assert.False(t, ok)
+	_, ok = retryAfter(nil, now)
+	assert.False(t, ok)
+}
+
+func TestMURetryAfter(t *testing.T) {
+	s, err := signer.FromPath("./keys/wallet.json")
+	assert.NoError(t, err)
+
+	t.Run("Waits", func(t *testing.T) {
+		var attempts atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if attempts.Add(1) == 1 {
+				w.Header().Set("Retry-After", "1")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			_, err := w.Write([]byte(`{"id": "mockMessageID"}`))
+			assert.NoError(t, err)
+		}))
+		defer srv.Close()
+
+		ao, err := New(WithMUURL(srv.URL), WithMURetry(RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}))
+		assert.NoError(t, err)
+
+		start := time.Now()
+		id, err := ao.SendMessage(testProcess, "data", nil, "", s)
+		assert.NoError(t, err)
+		assert.Equal(t, "mockMessageID", id)
+		assert.GreaterOrEqual(t, time.Since(start), time.Second)
+	})
+
+	t.Run("AbortsBeforeDeadline", func(t *testing.T) {
+		var attempts atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts.Add(1)
+			w.Header().Set("Retry-After", "10")
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer srv.Close()
+
+		ao, err := New(WithMUURL(srv.URL), WithMUTimeout(200*time.Millisecond), WithMURetry(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}))
+		assert.NoError(t, err)
+
+		start := time.Now()
+		_, err = ao.SendMessage(testProcess, "data", nil, "", s)
+		var aoErr *AOError
+		assert.True(t, errors.As(err, &aoErr))
+		assert.Equal(t, http.StatusServiceUnavailable, aoErr.StatusCode)
+		assert.Equal(t, int32(1), attempts.Load())
+		assert.Less(t, time.Since(start), 200*time.Millisecond)
+	})
+}
+
+func TestWithRetryIf(t *testing.T) {
+	t.Run("NeverRetrySend", func(t *testing.T) {
+		var attempts atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts.Add(1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer srv.Close()
+
+		ao, err := New(WithMUURL(srv.URL), WithMURetry(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}),
+			WithRetryIf(func(req *http.Request, resp *http.Response, err error) bool { return req.Method != http.MethodPost }))
+		assert.NoError(t, err)
+		_, err = ao.SendMessage(testProcess, "", nil, "", setupSigner(t))
+		assert.ErrorIs(t, err, ErrServerError)
+		assert.Equal(t, int32(1), attempts.Load())
+	})
+
+	t.Run("RetryMore", func(t *testing.T) {
+		var attempts atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if attempts.Add(1) < 3 {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			_, err := w.Write([]byte(`{"Messages": [{"Data": "1"}], "Spawns": [], "Outputs": [], "Error": "", "GasUsed": 0}`))
+			assert.NoError(t, err)
+		}))
+		defer srv.Close()
+
+		var seen []int
+		ao, err := New(WithCUURL(srv.URL), WithCURetry(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}),
+			WithRetryIf(func(req *http.Request, resp *http.Response, err error) bool {
+				assert.NotNil(t, req.Context())
+				seen = append(seen, resp.StatusCode)
+				return err != nil || resp.StatusCode >= 500
+			}))
+		assert.NoError(t, err)
+		_, err = ao.LoadResult(testProcess, testMessage)
+		assert.NoError(t, err)
+		assert.Equal(t, int32(3), attempts.Load())
+		assert.Equal(t, []int{500, 500}, seen)
+	})
+
+	t.Run("DryRunSentOnce", func(t *testing.T) {
+		var attempts atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts.Add(1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer srv.Close()
+
+		ao, err := New(WithCUURL(srv.URL), WithCURetry(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}),
+			WithRetryIf(func(*http.Request, *http.Response, error) bool { return true }))
+		assert.NoError(t, err)
+		_, err = ao.DryRun(Message{Target: testProcess})
+		assert.Error(t, err)
+		assert.Equal(t, int32(1), attempts.Load())
+	})
+}
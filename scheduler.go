@@ -0,0 +1,75 @@
+package aogo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+const schedulerLocationQuery = `query ($owners: [String!]!) {
+  transactions(owners: $owners, tags: [{name: "Type", values: ["Scheduler-Location"]}], sort: HEIGHT_DESC, first: 1) {
+    edges {
+      node {
+        id
+        anchor
+        recipient
+        owner { address key }
+        tags { name value }
+        data { size type }
+        block { id height timestamp }
+      }
+    }
+  }
+}`
+
+// SchedulerURL returns the URL of the scheduler unit process is pinned to. It reads the Scheduler tag of the
+// process, then the Url tag of the latest Scheduler-Location record that scheduler published on the gateway.
+// The URL is cached per process for the life of the client.
+func (ao *AO) SchedulerURL(process string) (string, error) {
+	return ao.SchedulerURLContext(context.Background(), process)
+}
+
+// SchedulerURLContext is like SchedulerURL but aborts the lookup when ctx is done.
+func (ao *AO) SchedulerURLContext(ctx context.Context, process string) (string, error) {
+	if err := validateID("process", process); err != nil {
+		return "", err
+	}
+	if u, ok := ao.schedulers.Load(process); ok {
+		return u.(string), nil
+	}
+
+	txs, err := ao.gateway.transactionsByID(ctx, process)
+	if err != nil {
+		return "", fmt.Errorf("scheduler of %s: %w", process, err)
+	}
+	if len(txs) == 0 {
+		return "", fmt.Errorf("scheduler of %s: process not indexed by the gateway", process)
+	}
+	scheduler, ok := FindTag(txs[0].Tags, "Scheduler")
+	if !ok || scheduler == "" {
+		return "", fmt.Errorf("scheduler of %s: process has no Scheduler tag", process)
+	}
+	u, err := ao.gateway.schedulerLocation(ctx, scheduler)
+	if err != nil {
+		return "", fmt.Errorf("scheduler of %s: %w", process, err)
+	}
+	ao.schedulers.Store(process, u)
+	return u, nil
+}
+
+// schedulerLocation returns the Url of the latest Scheduler-Location record published by scheduler, without a
+// trailing slash.
+func (g *Gateway) schedulerLocation(ctx context.Context, scheduler string) (string, error) {
+	txs, err := g.transactions(ctx, schedulerLocationQuery, map[string]any{"owners": []string{scheduler}})
+	if err != nil {
+		return "", err
+	}
+	if len(txs) == 0 {
+		return "", fmt.Errorf("scheduler %s has no Scheduler-Location record", scheduler)
+	}
+	u, ok := FindTag(txs[0].Tags, "Url")
+	if !ok || u == "" {
+		return "", fmt.Errorf("scheduler %s: Scheduler-Location record %s has no Url tag", scheduler, txs[0].ID)
+	}
+	return strings.TrimRight(u, "/"), nil
+}
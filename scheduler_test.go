@@ -0,0 +1,64 @@
+package aogo
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchedulerURL(t *testing.T) {
+	lookups := 0
+	location := `{"name": "Url", "value": "https://su.example/"}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lookups++
+		var req graphQLRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		var body string
+		switch {
+		case req.Variables["ids"] != nil:
+			assert.Equal(t, []any{testProcess}, req.Variables["ids"])
+			body = `{"id": "` + testProcess + `", "tags": [{"name": "Scheduler", "value": "` + SCHEDULER + `"}]}`
+		default:
+			assert.Contains(t, req.Query, "Scheduler-Location")
+			assert.Equal(t, []any{SCHEDULER}, req.Variables["owners"])
+			body = `{"id": "` + testMessage + `", "tags": [{"name": "Type", "value": "Scheduler-Location"}, ` + location + `]}`
+		}
+		_, err := w.Write([]byte(`{"data": {"transactions": {"edges": [{"node": ` + body + `}]}}}`))
+		assert.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	ao := &AO{gateway: newGateway(srv.URL)}
+	u, err := ao.SchedulerURL(testProcess)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://su.example", u)
+	assert.Equal(t, 2, lookups)
+
+	u, err = ao.SchedulerURL(testProcess)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://su.example", u)
+	assert.Equal(t, 2, lookups, "the URL is cached per process")
+
+	location = `{"name": "Time-To-Live", "value": "3600000"}`
+	_, err = (&AO{gateway: newGateway(srv.URL)}).SchedulerURL(testProcess)
+	assert.ErrorContains(t, err, "no Url tag")
+
+	_, err = ao.SchedulerURL("bad")
+	assert.ErrorIs(t, err, ErrInvalidID)
+}
+
+func TestSchedulerURLNoSchedulerTag(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(strings.ReplaceAll(`{"data": {"transactions": {"edges": [{"node": {"id": "ID", "tags": []}}]}}}`, "ID", testProcess)))
+		assert.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	ao := &AO{gateway: newGateway(srv.URL)}
+	_, err := ao.SchedulerURL(testProcess)
+	assert.ErrorContains(t, err, "no Scheduler tag")
+}
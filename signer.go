@@ -0,0 +1,46 @@
+package aogo
+
+import (
+	"fmt"
+
+	"github.com/liteseed/goar/crypto"
+	"github.com/liteseed/goar/signer"
+)
+
+// NewSigner builds a signer from the JSON of an Arweave JWK, for keys that come from a secret or an
+// environment variable rather than a file. Errors never quote the key.
+func NewSigner(jwk []byte) (*signer.Signer, error) {
+	s, err := signer.FromJWK(jwk)
+	if err != nil {
+		return nil, fmt.Errorf("%w: cannot decode JWK", ErrInvalidSigner)
+	}
+	return s, nil
+}
+
+// Address returns the Arweave address of s, the base64url SHA-256 of its public key modulus. This is the
+// owner of the processes s spawns. A nil s falls back to the default signer.
+func (ao *AO) Address(s *signer.Signer) (string, error) {
+	s = ao.signerOr(s)
+	if s == nil || s.PublicKey == nil {
+		return "", fmt.Errorf("%w: signer is required", ErrInvalidSigner)
+	}
+	return crypto.GetAddressFromPublicKey(s.PublicKey), nil
+}
+
+// SignerType returns the ANS-104 signature type of the default signer, the one set by WithDataItemSigner or
+// else by WithSigner, for example data_item.Arweave or data_item.Ethereum. It returns 0 without a default signer.
+func (ao *AO) SignerType() int {
+	s := ao.itemSignerOr(nil)
+	if s == nil {
+		return 0
+	}
+	return s.SignatureType()
+}
+
+// signerOr returns s, or the default signer of ao when s is nil.
+func (ao *AO) signerOr(s *signer.Signer) *signer.Signer {
+	if s == nil {
+		return ao.signer
+	}
+	return s
+}
@@ -0,0 +1,72 @@
+package aogo
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/liteseed/goar/transaction/data_item"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSigner(t *testing.T) {
+	jwk, err := os.ReadFile("./keys/wallet.json")
+	assert.NoError(t, err)
+
+	s, err := NewSigner(jwk)
+	assert.NoError(t, err)
+	assert.Equal(t, setupSigner(t).Address, s.Address)
+
+	secret := []byte(`{"kty": "RSA", "n": "not-a-key", "d": "super-secret"}`)
+	_, err = NewSigner(secret)
+	assert.ErrorIs(t, err, ErrInvalidSigner)
+	assert.NotContains(t, err.Error(), "super-secret")
+}
+
+func TestWithSignerBytes(t *testing.T) {
+	jwk, err := os.ReadFile("./keys/wallet.json")
+	assert.NoError(t, err)
+
+	var owner string
+	muServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		item, err := data_item.Decode(b)
+		assert.NoError(t, err)
+		owner = item.Owner
+		_, err = w.Write([]byte(`{"id": "mockMessageID"}`))
+		assert.NoError(t, err)
+	}))
+	defer muServer.Close()
+
+	ao, err := New(WithMUURL(muServer.URL), WithSignerBytes(jwk))
+	assert.NoError(t, err)
+	_, err = ao.SendMessage(testProcess, "", nil, "", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, setupSigner(t).Owner(), owner)
+
+	_, err = New(WithSignerBytes([]byte("{}")))
+	assert.ErrorIs(t, err, ErrInvalidSigner)
+}
+
+func TestAddress(t *testing.T) {
+	// The SHA-256 of the modulus of keys/wallet.json.
+	const address = "-OOm6g8SzKNzIT7sxIje1erZKXehVe8wbv5Xj9cK_8Y"
+
+	ao, err := New()
+	assert.NoError(t, err)
+	got, err := ao.Address(setupSigner(t))
+	assert.NoError(t, err)
+	assert.Equal(t, address, got)
+
+	_, err = ao.Address(nil)
+	assert.ErrorIs(t, err, ErrInvalidSigner)
+
+	ao, err = New(WithSigner(setupSigner(t)))
+	assert.NoError(t, err)
+	got, err = ao.Address(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, address, got)
+}
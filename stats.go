@@ -0,0 +1,66 @@
+package aogo
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// CallStats describes the HTTP requests a single call made, for latency histograms and for finding out which
+// endpoint served it after failover. Pass one to a call through WithCallStats; it is filled in by the time the
+// call returns.
+type CallStats struct {
+	// Unit is the unit of the last request to finish.
+	Unit Unit
+	// Endpoint is the scheme and host of the last request to finish, the endpoint that served the call unless it
+	// failed.
+	Endpoint string
+	// Attempts counts the requests made, including retries and failovers. It is 0 when the call made no request,
+	// for example when its result was cached.
+	Attempts int
+	// Retried reports whether more than one request was made.
+	Retried bool
+	// Duration runs from the start of the first request to the end of the last one to finish, including the waits
+	// between retries. Concurrent requests overlap in it rather than add up.
+	Duration time.Duration
+}
+
+type callStatsKey struct{}
+
+// callStats guards the CallStats of a call, which concurrent requests may update together.
+type callStats struct {
+	mu    sync.Mutex
+	stats *CallStats
+	start time.Time
+	end   time.Time
+}
+
+// WithCallStats returns a context that makes the XxxContext calls given it record their requests in stats.
+// stats must not be read until the call has returned.
+func WithCallStats(ctx context.Context, stats *CallStats) context.Context {
+	return context.WithValue(ctx, callStatsKey{}, &callStats{stats: stats})
+}
+
+// recordCall adds req, sent to unit between start and end, to the CallStats of its context, if any.
+func recordCall(req *http.Request, unit Unit, start time.Time, end time.Time) {
+	c, ok := req.Context().Value(callStatsKey{}).(*callStats)
+	if !ok {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.start.IsZero() || start.Before(c.start) {
+		c.start = start
+	}
+	s := c.stats
+	s.Attempts++
+	s.Retried = s.Attempts > 1
+	// Requests may finish out of order, so one ending before the latest does not shorten the call.
+	if !end.Before(c.end) {
+		c.end = end
+		s.Unit = unit
+		s.Endpoint = req.URL.Scheme + "://" + req.URL.Host
+	}
+	s.Duration = c.end.Sub(c.start)
+}
@@ -0,0 +1,84 @@
+package aogo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithCallStats(t *testing.T) {
+	t.Run("Failover", func(t *testing.T) {
+		bad := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer bad.Close()
+		good := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, err := w.Write([]byte(`{"Messages": [], "Spawns": [], "Outputs": [], "GasUsed": 0}`))
+			assert.NoError(t, err)
+		}))
+		defer good.Close()
+
+		ao, err := New(WithCUURLs([]string{bad.URL, good.URL}), WithCURetry(NoRetry))
+		assert.NoError(t, err)
+		var stats CallStats
+		_, err = ao.LoadResultContext(WithCallStats(context.Background(), &stats), testProcess, testMessage)
+		assert.NoError(t, err)
+		assert.Equal(t, UnitCU, stats.Unit)
+		assert.Equal(t, good.URL, stats.Endpoint)
+		assert.Equal(t, 2, stats.Attempts)
+		assert.True(t, stats.Retried)
+		assert.Positive(t, stats.Duration)
+	})
+
+	t.Run("Retry", func(t *testing.T) {
+		var requests atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if requests.Add(1) == 1 {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			_, err := w.Write([]byte(`{"id": "` + testMessage + `"}`))
+			assert.NoError(t, err)
+		}))
+		defer srv.Close()
+
+		ao, err := New(WithMUURL(srv.URL), WithMURetry(RetryPolicy{MaxAttempts: 2, Backoff: Constant(20 * time.Millisecond)}))
+		assert.NoError(t, err)
+		var stats CallStats
+		_, err = ao.SendMessageContext(WithCallStats(context.Background(), &stats), testProcess, "data", nil, "", setupSigner(t))
+		assert.NoError(t, err)
+		assert.Equal(t, UnitMU, stats.Unit)
+		assert.Equal(t, srv.URL, stats.Endpoint)
+		assert.Equal(t, 2, stats.Attempts)
+		assert.GreaterOrEqual(t, stats.Duration, 20*time.Millisecond)
+	})
+
+	t.Run("NoRequest", func(t *testing.T) {
+		ao := &AO{compute: &stubCU{results: map[string]*Response{testMessage: {}}}}
+		var stats CallStats
+		_, err := ao.LoadResultContext(WithCallStats(context.Background(), &stats), testProcess, testMessage)
+		assert.NoError(t, err)
+		assert.Zero(t, stats.Attempts)
+		assert.False(t, stats.Retried)
+	})
+
+	t.Run("OutOfOrder", func(t *testing.T) {
+		var stats CallStats
+		ctx := WithCallStats(context.Background(), &stats)
+		slow, err := http.NewRequestWithContext(ctx, "GET", "http://slow/result", nil)
+		assert.NoError(t, err)
+		fast, err := http.NewRequestWithContext(ctx, "GET", "http://fast/result", nil)
+		assert.NoError(t, err)
+		start := time.Unix(0, 0)
+		recordCall(slow, UnitCU, start, start.Add(time.Second))
+		recordCall(fast, UnitCU, start.Add(10*time.Millisecond), start.Add(20*time.Millisecond))
+		assert.Equal(t, time.Second, stats.Duration)
+		assert.Equal(t, "http://slow", stats.Endpoint)
+		assert.Equal(t, 2, stats.Attempts)
+	})
+}
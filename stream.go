@@ -0,0 +1,93 @@
+package aogo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// DefaultMaxResponseBytes is the largest response body the client buffers unless set with WithMaxResponseBytes.
+// Larger results can still be read with LoadResultStream.
+const DefaultMaxResponseBytes = 32 << 20
+
+// readBody reads r to the end, failing with ErrResponseTooLarge once it exceeds limit bytes.
+// A zero limit means DefaultMaxResponseBytes and a negative one no limit.
+func readBody(r io.Reader, limit int64) ([]byte, error) {
+	if limit == 0 {
+		limit = DefaultMaxResponseBytes
+	}
+	if limit < 0 {
+		return io.ReadAll(r)
+	}
+	b, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(b)) > limit {
+		return nil, fmt.Errorf("%w: body exceeds %d bytes", ErrResponseTooLarge, limit)
+	}
+	return b, nil
+}
+
+// LoadResultStream is like LoadResult but returns the raw result body as the CU sends it, without buffering or
+// decoding it, so that results too large for LoadResult can be decoded incrementally. The caller must Close
+// the returned reader. The CU timeout, if any, also bounds reading the body.
+//
+// With a compute unit set by NewWithUnits, the result is loaded through it and streamed as JSON.
+func (ao *AO) LoadResultStream(process string, message string) (io.ReadCloser, error) {
+	return ao.LoadResultStreamContext(context.Background(), process, message)
+}
+
+// LoadResultStreamContext is like LoadResultStream but aborts when ctx is done, including while the body is read.
+func (ao *AO) LoadResultStreamContext(ctx context.Context, process string, message string) (io.ReadCloser, error) {
+	if ao.compute != nil {
+		res, err := ao.compute.LoadResult(process, message)
+		if err != nil {
+			return nil, err
+		}
+		b, err := json.Marshal(res)
+		if err != nil {
+			return nil, err
+		}
+		return io.NopCloser(bytes.NewReader(b)), nil
+	}
+	return ao.cu.loadResultStream(ctx, process, message)
+}
+
+func (cu *CU) loadResultStream(ctx context.Context, process string, message string) (io.ReadCloser, error) {
+	if err := validateID("process", process); err != nil {
+		return nil, err
+	}
+	if err := validateID("message", message); err != nil {
+		return nil, err
+	}
+	resp, cancel, err := cu.stream(ctx, fmt.Sprintf("/result/%s?process-id=%s", message, process))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		defer cancel()
+		defer resp.Body.Close()
+		b, err := readBody(resp.Body, cu.maxResponseBytes)
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("load result: %w", newAOError(UnitCU, resp, b, cu.maxErrorBody))
+	}
+	return &streamBody{ReadCloser: resp.Body, cancel: cancel}, nil
+}
+
+// streamBody releases the request context of a streamed response when it is closed.
+type streamBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *streamBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,156 @@
+package aogo
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/liteseed/goar/signer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadBody(t *testing.T) {
+	b, err := readBody(strings.NewReader("12345"), 5)
+	assert.NoError(t, err)
+	assert.Equal(t, "12345", string(b))
+
+	_, err = readBody(strings.NewReader("123456"), 5)
+	assert.ErrorIs(t, err, ErrResponseTooLarge)
+	assert.ErrorContains(t, err, "5 bytes")
+
+	b, err = readBody(strings.NewReader("123456"), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, "123456", string(b))
+}
+
+func TestWithMaxResponseBytes(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/graphql" {
+			_, err := w.Write([]byte(`{"data": {"transactions": {"edges": [{"node": {"id": "` + testMessage + `"}}]}}}`))
+			assert.NoError(t, err)
+			return
+		}
+		_, err := w.Write([]byte(`{"id": "` + testMessage + `", "Messages": [{"Data": "` + strings.Repeat("x", 100) + `"}]}`))
+		assert.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	t.Run("Exceeded", func(t *testing.T) {
+		ao, err := New(WithCUURL(srv.URL), WithMUURL(srv.URL), WithGatewayURL(srv.URL), WithMaxResponseBytes(64))
+		assert.NoError(t, err)
+		_, err = ao.LoadResult(testProcess, testMessage)
+		assert.ErrorIs(t, err, ErrResponseTooLarge)
+		_, err = ao.DryRun(Message{Target: testProcess})
+		assert.ErrorIs(t, err, ErrResponseTooLarge)
+		_, err = ao.GetTransaction(testMessage)
+		assert.ErrorIs(t, err, ErrResponseTooLarge)
+
+		s, err := signer.FromPath("./keys/wallet.json")
+		assert.NoError(t, err)
+		_, err = ao.SendMessage(testProcess, "", nil, "", s)
+		assert.ErrorIs(t, err, ErrResponseTooLarge)
+	})
+
+	t.Run("Within", func(t *testing.T) {
+		ao, err := New(WithCUURL(srv.URL), WithGatewayURL(srv.URL), WithMaxResponseBytes(1024))
+		assert.NoError(t, err)
+		_, err = ao.LoadResult(testProcess, testMessage)
+		assert.NoError(t, err)
+		_, err = ao.GetTransaction(testMessage)
+		assert.NoError(t, err)
+	})
+
+	t.Run("StreamNotLimited", func(t *testing.T) {
+		ao, err := New(WithCUURL(srv.URL), WithMaxResponseBytes(64))
+		assert.NoError(t, err)
+		r, err := ao.LoadResultStream(testProcess, testMessage)
+		assert.NoError(t, err)
+		defer r.Close()
+		b, err := io.ReadAll(r)
+		assert.NoError(t, err)
+		assert.Greater(t, len(b), 64)
+	})
+}
+
+func TestLoadResultStream(t *testing.T) {
+	t.Run("Stream", func(t *testing.T) {
+		// A result well above what a single read returns.
+		body := `{"Messages": [], "Spawns": [], "Outputs": ["` + strings.Repeat("x", 1<<20) + `"], "Error": "", "GasUsed": 0}`
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/result/"+testMessage, r.URL.Path)
+			assert.Equal(t, testProcess, r.URL.Query().Get("process-id"))
+			_, err := w.Write([]byte(body))
+			assert.NoError(t, err)
+		}))
+		defer srv.Close()
+
+		ao, err := New(WithCUURL(srv.URL))
+		assert.NoError(t, err)
+		r, err := ao.LoadResultStream(testProcess, testMessage)
+		assert.NoError(t, err)
+		defer r.Close()
+
+		var res Response
+		assert.NoError(t, json.NewDecoder(r).Decode(&res))
+		assert.Len(t, res.Outputs[0], 1<<20)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			_, err := w.Write([]byte("not found"))
+			assert.NoError(t, err)
+		}))
+		defer srv.Close()
+
+		ao, err := New(WithCUURL(srv.URL))
+		assert.NoError(t, err)
+		_, err = ao.LoadResultStream(testProcess, testMessage)
+		assert.ErrorIs(t, err, ErrProcessNotFound)
+		assert.ErrorContains(t, err, "not found")
+	})
+
+	t.Run("Cancelled", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, err := w.Write([]byte(`{"Messages": [`))
+			assert.NoError(t, err)
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		}))
+		defer srv.Close()
+
+		ao, err := New(WithCUURL(srv.URL))
+		assert.NoError(t, err)
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		r, err := ao.LoadResultStreamContext(ctx, testProcess, testMessage)
+		assert.NoError(t, err)
+		defer r.Close()
+		_, err = io.ReadAll(r)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("ComputeUnit", func(t *testing.T) {
+		ao, err := NewWithUnits(&stubCU{results: map[string]*Response{testMessage: {Messages: []ResultMessage{{Data: "1"}}}}}, nil)
+		assert.NoError(t, err)
+		r, err := ao.LoadResultStream(testProcess, testMessage)
+		assert.NoError(t, err)
+		defer r.Close()
+
+		var res Response
+		assert.NoError(t, json.NewDecoder(r).Decode(&res))
+		assert.Equal(t, "1", res.Messages[0].Data)
+	})
+
+	t.Run("InvalidID", func(t *testing.T) {
+		ao, err := New()
+		assert.NoError(t, err)
+		_, err = ao.LoadResultStream(testProcess, "short")
+		assert.ErrorIs(t, err, ErrInvalidID)
+	})
+}
@@ -0,0 +1,164 @@
+package aogo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/liteseed/goar/tag"
+)
+
+type SU struct {
+	client       *http.Client
+	url          string
+	timeout      time.Duration
+	retry        RetryPolicy
+	logger       *slog.Logger
+	observer     Observer
+	breaker      *circuitBreaker
+	maxErrorBody int
+	// maxResponseBytes caps buffered response bodies, see WithMaxResponseBytes.
+	maxResponseBytes int64
+	// header is added to every request.
+	header http.Header
+}
+
+func newSU(url string) SU {
+	return SU{
+		client: http.DefaultClient,
+		url:    url,
+		retry:  DefaultRetryPolicy,
+	}
+}
+
+// SUOwner is the signer of a scheduled message or assignment.
+type SUOwner struct {
+	Address string `json:"address"`
+	Key     string `json:"key"`
+}
+
+// SUMessage is a data item as stored by the scheduler.
+type SUMessage struct {
+	ID        string    `json:"id"`
+	Owner     SUOwner   `json:"owner"`
+	Data      string    `json:"data"`
+	Tags      []tag.Tag `json:"tags"`
+	Signature string    `json:"signature"`
+	Anchor    string    `json:"anchor"`
+	Target    string    `json:"target"`
+}
+
+// SUNode pairs a message with the assignment that placed it in the process' schedule.
+type SUNode struct {
+	Message    SUMessage `json:"message"`
+	Assignment SUMessage `json:"assignment"`
+}
+
+type SUEdge struct {
+	Node   SUNode `json:"node"`
+	Cursor string `json:"cursor"`
+}
+
+type SUPageInfo struct {
+	HasNextPage bool `json:"has_next_page"`
+}
+
+// SUPage is one page of a process' message log.
+type SUPage struct {
+	PageInfo SUPageInfo `json:"page_info"`
+	Edges    []SUEdge   `json:"edges"`
+	// NextCursor is the cursor to pass as from to fetch the following page, or empty on the last page.
+	NextCursor string `json:"-"`
+}
+
+// GetMessages reads the ordered message log of process from the SU. from and to are optional cursors.
+func (su *SU) GetMessages(process string, from string, to string) (*SUPage, error) {
+	return su.getMessages(context.Background(), process, from, to)
+}
+
+func (su *SU) getMessages(ctx context.Context, process string, from string, to string) (*SUPage, error) {
+	query := url.Values{}
+	if from != "" {
+		query.Set("from", from)
+	}
+	if to != "" {
+		query.Set("to", to)
+	}
+	u := fmt.Sprintf("%s/%s", su.url, process)
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	ctx, cancel := requestContext(ctx, su.timeout)
+	defer cancel()
+	resp, err := su.breaker.do(su.url, func() (*http.Response, error) {
+		return su.retry.do(ctx, su.roundTrip, func() (*http.Request, error) {
+			req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+			if err != nil {
+				return nil, err
+			}
+			req.Header.Set("accept", "application/json")
+			return req, nil
+		}, retryGatewayError)
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	b, err := readBody(resp.Body, su.maxResponseBytes)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, fmt.Errorf("get messages: %w", newAOError(UnitSU, resp, b, su.maxErrorBody))
+	}
+
+	var page SUPage
+	err = json.Unmarshal(b, &page)
+	if err != nil {
+		return nil, unmarshalError("response", err, b, su.maxErrorBody)
+	}
+	if page.PageInfo.HasNextPage && len(page.Edges) > 0 {
+		page.NextCursor = page.Edges[len(page.Edges)-1].Cursor
+	}
+	return &page, nil
+}
+
+// hasMessage reports whether the SU has scheduled message id on process. It makes a single attempt, as it is
+// itself called between the retries of a send.
+func (su *SU) hasMessage(ctx context.Context, process string, id string) (bool, error) {
+	ctx, cancel := requestContext(ctx, su.timeout)
+	defer cancel()
+	u := fmt.Sprintf("%s/%s?process-id=%s", su.url, id, url.QueryEscape(process))
+	resp, err := su.breaker.do(su.url, func() (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("accept", "application/json")
+		return su.roundTrip(req)
+	})
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	b, err := readBody(resp.Body, su.maxResponseBytes)
+	if err != nil {
+		return false, err
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return false, nil
+	case resp.StatusCode >= http.StatusBadRequest:
+		return false, fmt.Errorf("get message: %w", newAOError(UnitSU, resp, b, su.maxErrorBody))
+	}
+	return true, nil
+}
+
+func (su *SU) roundTrip(req *http.Request) (*http.Response, error) {
+	return sendRequest(su.client, su.logger, su.observer, UnitSU, su.header, req)
+}
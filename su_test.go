@@ -0,0 +1,67 @@
+package aogo
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetMessages(t *testing.T) {
+	t.Run("Page", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodGet, r.Method)
+			assert.Equal(t, "/process", r.URL.Path)
+			assert.Equal(t, "cursor-0", r.URL.Query().Get("from"))
+			assert.Equal(t, "", r.URL.Query().Get("to"))
+			_, err := w.Write([]byte(`{
+				"page_info": {"has_next_page": true},
+				"edges": [
+					{"cursor": "cursor-1", "node": {"message": {"id": "m1", "owner": {"address": "a1"}, "tags": [{"name": "Action", "value": "Eval"}]}, "assignment": {"id": "as1"}}},
+					{"cursor": "cursor-2", "node": {"message": {"id": "m2"}, "assignment": {"id": "as2"}}}
+				]
+			}`))
+			assert.NoError(t, err)
+		}))
+		defer srv.Close()
+
+		ao := &AO{su: newSU(srv.URL)}
+		page, err := ao.GetMessages("process", "cursor-0", "")
+		assert.NoError(t, err)
+		assert.True(t, page.PageInfo.HasNextPage)
+		assert.Len(t, page.Edges, 2)
+		assert.Equal(t, "m1", page.Edges[0].Node.Message.ID)
+		assert.Equal(t, "a1", page.Edges[0].Node.Message.Owner.Address)
+		assert.Equal(t, "Eval", page.Edges[0].Node.Message.Tags[0].Value)
+		assert.Equal(t, "as2", page.Edges[1].Node.Assignment.ID)
+		assert.Equal(t, "cursor-2", page.NextCursor)
+	})
+
+	t.Run("LastPage", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, err := w.Write([]byte(`{"page_info": {"has_next_page": false}, "edges": [{"cursor": "cursor-1", "node": {"message": {"id": "m1"}}}]}`))
+			assert.NoError(t, err)
+		}))
+		defer srv.Close()
+
+		ao := &AO{su: newSU(srv.URL)}
+		page, err := ao.GetMessages("process", "", "")
+		assert.NoError(t, err)
+		assert.Equal(t, "", page.NextCursor)
+	})
+
+	t.Run("HTTPErrorResponse", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer srv.Close()
+
+		ao := &AO{su: newSU(srv.URL)}
+		_, err := ao.GetMessages("process", "", "")
+		var aoErr *AOError
+		assert.True(t, errors.As(err, &aoErr))
+		assert.Equal(t, UnitSU, aoErr.Unit)
+	})
+}
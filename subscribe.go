@@ -0,0 +1,144 @@
+package aogo
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+)
+
+var (
+	// subscribePollInterval is how often a subscription that has caught up asks the SU for new messages.
+	subscribePollInterval = time.Second
+	// subscribeBackoff spaces the attempts of a subscription to reach the SU or CU again after a failure.
+	subscribeBackoff Backoff = ExponentialJitter(500*time.Millisecond, 30*time.Second)
+)
+
+// SubscribeFilter selects the messages whose results a subscription delivers by the message as scheduled.
+type SubscribeFilter func(message SUMessage) bool
+
+// TagFilter selects the messages carrying the tag name with value.
+func TagFilter(name string, value string) SubscribeFilter {
+	return func(message SUMessage) bool {
+		v, ok := FindTag(message.Tags, name)
+		return ok && v == value
+	}
+}
+
+// ActionFilter selects the messages whose Action tag is action, such as Credit-Notice.
+func ActionFilter(action string) SubscribeFilter {
+	return TagFilter("Action", action)
+}
+
+// Subscribe delivers on the returned channel the result of every message scheduled on process after the call,
+// in schedule order. It follows the message log of the SU and loads each result from the CU, so no polling loop
+// is needed on the caller's side. A message whose evaluation failed is delivered as a result reporting its
+// Error. When the SU or CU cannot be reached the subscription tries again with a growing backoff, resuming
+// where it stopped.
+//
+// With filters, only the results of the messages every filter selects are delivered. Filtering happens on the
+// client, after the SU returns the message log but before the results are loaded, so the CU is only asked for
+// results that are delivered.
+//
+// The returned func cancels the subscription; the channel is closed once it has stopped. Close cancels every
+// subscription of ao. The channel must be drained, as a subscription waits for each result to be received.
+func (ao *AO) Subscribe(process string, filters ...SubscribeFilter) (<-chan *Response, func(), error) {
+	if err := validateID("process", process); err != nil {
+		return nil, nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	results := make(chan *Response)
+	from := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	err := ao.background.goFunc(func(background context.Context) {
+		defer close(results)
+		stop := context.AfterFunc(background, cancel)
+		defer stop()
+		ao.subscribe(ctx, process, from, filters, results)
+	})
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	return results, cancel, nil
+}
+
+// subscribe follows the message log of process from the cursor from, sending the result of every message that
+// filters select to results until ctx is done.
+func (ao *AO) subscribe(ctx context.Context, process string, from string, filters []SubscribeFilter, results chan<- *Response) {
+	cursor := from
+	// delivered holds the messages at cursor that were already sent, as the SU may return them again.
+	delivered := map[string]bool{}
+	failures := 0
+	for ctx.Err() == nil {
+		page, err := ao.su.getMessages(ctx, process, cursor, "")
+		if err != nil {
+			failures++
+			sleepContext(ctx, subscribeBackoff.NextDelay(failures))
+			continue
+		}
+		failed := false
+		for _, e := range page.Edges {
+			id := e.Node.Message.ID
+			if e.Cursor == cursor && delivered[id] {
+				continue
+			}
+			if selected(e.Node.Message, filters) {
+				res, err := ao.subscriptionResult(ctx, process, id)
+				if err != nil {
+					failed = true
+					break
+				}
+				select {
+				case results <- res:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if e.Cursor != cursor {
+				cursor = e.Cursor
+				delivered = map[string]bool{}
+			}
+			delivered[id] = true
+		}
+		switch {
+		case failed:
+			failures++
+			sleepContext(ctx, subscribeBackoff.NextDelay(failures))
+		case !page.PageInfo.HasNextPage:
+			failures = 0
+			sleepContext(ctx, subscribePollInterval)
+		default:
+			failures = 0
+		}
+	}
+}
+
+// selected reports whether every filter selects message.
+func selected(message SUMessage, filters []SubscribeFilter) bool {
+	for _, f := range filters {
+		if !f(message) {
+			return false
+		}
+	}
+	return true
+}
+
+// subscriptionResult loads the result of message, turning a ProcessError back into the result reporting it.
+func (ao *AO) subscriptionResult(ctx context.Context, process string, message string) (*Response, error) {
+	res, err := ao.loadResult(ctx, process, message, nil)
+	var pErr *ProcessError
+	if errors.As(err, &pErr) {
+		return &Response{Error: pErr.Message, GasUsed: pErr.GasUsed}, nil
+	}
+	return res, err
+}
+
+// sleepContext waits for d or until ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+	case <-t.C:
+	}
+}
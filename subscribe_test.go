@@ -0,0 +1,158 @@
+package aogo
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/liteseed/goar/tag"
+	"github.com/stretchr/testify/assert"
+)
+
+// suLog serves a message log from which the SU answers with every message at or after the from cursor.
+type suLog struct {
+	t        *testing.T
+	mu       sync.Mutex
+	messages []SUMessage
+	froms    []string
+	failures int
+}
+
+func (l *suLog) add(id string, tags ...tag.Tag) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.messages = append(l.messages, SUMessage{ID: id, Tags: tags})
+}
+
+func (l *suLog) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.failures > 0 {
+		l.failures--
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	from := r.URL.Query().Get("from")
+	l.froms = append(l.froms, from)
+	var page SUPage
+	for i, m := range l.messages {
+		// Cursors sort after the timestamp a subscription starts from.
+		cursor := fmt.Sprintf("9%012d", i)
+		if cursor < from {
+			continue
+		}
+		page.Edges = append(page.Edges, SUEdge{Node: SUNode{Message: m}, Cursor: cursor})
+	}
+	assert.NoError(l.t, json.NewEncoder(w).Encode(page))
+}
+
+func TestSubscribe(t *testing.T) {
+	interval, backoff := subscribePollInterval, subscribeBackoff
+	subscribePollInterval, subscribeBackoff = 10*time.Millisecond, Constant(10*time.Millisecond)
+	defer func() { subscribePollInterval, subscribeBackoff = interval, backoff }()
+
+	log := &suLog{t: t, failures: 1}
+	su := httptest.NewServer(log)
+	defer su.Close()
+	var cuFailures atomic.Int32
+	cuFailures.Store(1)
+	cu := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimPrefix(r.URL.Path, "/result/")
+		if id == testProcess && cuFailures.Add(-1) >= 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if id == testModule {
+			_, err := w.Write([]byte(`{"Messages": [], "Spawns": [], "Outputs": [], "Error": "boom", "GasUsed": 0}`))
+			assert.NoError(t, err)
+			return
+		}
+		_, err := w.Write([]byte(`{"Messages": [], "Spawns": [], "Outputs": ["` + id + `"], "GasUsed": 0}`))
+		assert.NoError(t, err)
+	}))
+	defer cu.Close()
+
+	ao, err := New(WithSUURL(su.URL), WithCUURL(cu.URL))
+	assert.NoError(t, err)
+	results, cancel, err := ao.Subscribe(testProcess)
+	assert.NoError(t, err)
+
+	log.add(testMessage)
+	log.add(testProcess)
+	log.add(testModule)
+	next := func() *Response {
+		select {
+		case res := <-results:
+			return res
+		case <-time.After(5 * time.Second):
+			t.Fatal("no result delivered")
+			return nil
+		}
+	}
+	// The SU failure and the CU failure are retried, and no message is delivered twice.
+	assert.Equal(t, []any{testMessage}, next().Outputs)
+	assert.Equal(t, []any{testProcess}, next().Outputs)
+	assert.Equal(t, "boom", next().Error)
+
+	cancel()
+	for range results {
+	}
+	log.mu.Lock()
+	assert.NotEmpty(t, log.froms)
+	assert.Regexp(t, `^1\d{12}$`, log.froms[0])
+	log.mu.Unlock()
+
+	_, _, err = ao.Subscribe("short")
+	assert.ErrorIs(t, err, ErrInvalidID)
+	assert.NoError(t, ao.Close())
+	_, _, err = ao.Subscribe(testProcess)
+	assert.ErrorIs(t, err, ErrClosed)
+}
+
+func TestSubscribeFilter(t *testing.T) {
+	interval := subscribePollInterval
+	subscribePollInterval = 10 * time.Millisecond
+	defer func() { subscribePollInterval = interval }()
+
+	log := &suLog{t: t}
+	su := httptest.NewServer(log)
+	defer su.Close()
+	var loaded []string
+	var mu sync.Mutex
+	cu := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimPrefix(r.URL.Path, "/result/")
+		mu.Lock()
+		loaded = append(loaded, id)
+		mu.Unlock()
+		_, err := w.Write([]byte(`{"Messages": [], "Spawns": [], "Outputs": ["` + id + `"], "GasUsed": 0}`))
+		assert.NoError(t, err)
+	}))
+	defer cu.Close()
+
+	ao, err := New(WithSUURL(su.URL), WithCUURL(cu.URL))
+	assert.NoError(t, err)
+	defer ao.Close()
+	results, cancel, err := ao.Subscribe(testProcess, ActionFilter("Credit-Notice"), TagFilter("X-Note", "rent"))
+	assert.NoError(t, err)
+	defer cancel()
+
+	log.add(testMessage, tag.Tag{Name: "Action", Value: "Transfer"}, tag.Tag{Name: "X-Note", Value: "rent"})
+	log.add(testModule, tag.Tag{Name: "Action", Value: "Credit-Notice"})
+	log.add(testProcess, tag.Tag{Name: "Action", Value: "Credit-Notice"}, tag.Tag{Name: "X-Note", Value: "rent"})
+
+	select {
+	case res := <-results:
+		assert.Equal(t, []any{testProcess}, res.Outputs)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no result delivered")
+	}
+	mu.Lock()
+	assert.Equal(t, []string{testProcess}, loaded)
+	mu.Unlock()
+}
@@ -0,0 +1,120 @@
+package aogo
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/liteseed/goar/tag"
+	"github.com/liteseed/goar/transaction/data_item"
+)
+
+// Tags builds a tag list with chainable calls, e.g. NewTags().Action("Transfer").Add("Recipient", addr).Build().
+// It is not safe for concurrent use.
+type Tags struct {
+	tags []tag.Tag
+}
+
+func NewTags() *Tags {
+	return &Tags{}
+}
+
+// TagsFromMap returns a builder holding every entry of m, sorted by name so the result is deterministic.
+func TagsFromMap(m map[string]string) *Tags {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	t := &Tags{tags: make([]tag.Tag, 0, len(m))}
+	for _, name := range names {
+		t.Add(name, m[name])
+	}
+	return t
+}
+
+// Add appends a tag.
+func (t *Tags) Add(name string, value string) *Tags {
+	t.tags = append(t.tags, tag.Tag{Name: name, Value: value})
+	return t
+}
+
+// Action appends the Action tag used by AO handlers to route a message.
+func (t *Tags) Action(name string) *Tags {
+	return t.Add("Action", name)
+}
+
+// Build returns a copy of the tags added so far.
+func (t *Tags) Build() []tag.Tag {
+	return append([]tag.Tag{}, t.tags...)
+}
+
+// FindTag returns the value of the first tag called name. The boolean reports whether such a tag exists.
+func FindTag(tags []tag.Tag, name string) (string, bool) {
+	for _, t := range tags {
+		if t.Name == name {
+			return t.Value, true
+		}
+	}
+	return "", false
+}
+
+// FindTagFold is like FindTag but compares names case-insensitively.
+func FindTagFold(tags []tag.Tag, name string) (string, bool) {
+	for _, t := range tags {
+		if strings.EqualFold(t.Name, name) {
+			return t.Value, true
+		}
+	}
+	return "", false
+}
+
+// DefaultVariant is the Variant tag of the messages the client signs unless set with WithVariant.
+const DefaultVariant = "ao.TN.1"
+
+// variantOr returns variant, or DefaultVariant if it is empty.
+func variantOr(variant string) string {
+	if variant == "" {
+		return DefaultVariant
+	}
+	return variant
+}
+
+// protocolTags returns the AO protocol tags of a data item of type typ: Data-Protocol, Variant and Type.
+func protocolTags(typ string, variant string) []tag.Tag {
+	return []tag.Tag{
+		{Name: "Data-Protocol", Value: "ao"},
+		{Name: "Variant", Value: variantOr(variant)},
+		{Name: "Type", Value: typ},
+	}
+}
+
+// missingTags returns the tags of defaults whose name tags does not already use, so required tags are added
+// without overriding the ones a caller set.
+func missingTags(tags []tag.Tag, defaults ...tag.Tag) []tag.Tag {
+	var missing []tag.Tag
+	for _, d := range defaults {
+		if _, ok := FindTag(tags, d.Name); !ok {
+			missing = append(missing, d)
+		}
+	}
+	return missing
+}
+
+// validateTags checks tags against the ANS-104 limits on the number of tags and the byte length of their names
+// and values, which the MU otherwise rejects with little explanation.
+func validateTags(tags []tag.Tag) error {
+	if len(tags) > data_item.MAX_TAGS {
+		return fmt.Errorf("%w: %d tags, at most %d allowed", ErrTooManyTags, len(tags), data_item.MAX_TAGS)
+	}
+	for _, t := range tags {
+		if len(t.Name) > data_item.MAX_TAG_KEY_LENGTH {
+			return fmt.Errorf("%w: name of tag %.64q is %d bytes, at most %d allowed", ErrTagTooLarge, t.Name, len(t.Name), data_item.MAX_TAG_KEY_LENGTH)
+		}
+		if len(t.Value) > data_item.MAX_TAG_VALUE_LENGTH {
+			return fmt.Errorf("%w: value of tag %q is %d bytes, at most %d allowed", ErrTagTooLarge, t.Name, len(t.Value), data_item.MAX_TAG_VALUE_LENGTH)
+		}
+	}
+	return nil
+}
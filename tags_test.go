@@ -0,0 +1,196 @@
+package aogo
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/liteseed/goar/tag"
+	"github.com/liteseed/goar/transaction/data_item"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTags(t *testing.T) {
+	t.Run("Chain", func(t *testing.T) {
+		tags := NewTags().Action("Transfer").Add("Recipient", "address").Add("Quantity", "10").Build()
+		assert.Equal(t, []tag.Tag{
+			{Name: "Action", Value: "Transfer"},
+			{Name: "Recipient", Value: "address"},
+			{Name: "Quantity", Value: "10"},
+		}, tags)
+	})
+
+	t.Run("FromMap", func(t *testing.T) {
+		tags := TagsFromMap(map[string]string{"b": "2", "c": "3", "a": "1"}).Action("Eval").Build()
+		assert.Equal(t, []tag.Tag{
+			{Name: "a", Value: "1"},
+			{Name: "b", Value: "2"},
+			{Name: "c", Value: "3"},
+			{Name: "Action", Value: "Eval"},
+		}, tags)
+	})
+
+	t.Run("BuildCopies", func(t *testing.T) {
+		builder := NewTags().Add("a", "1")
+		tags := builder.Build()
+		tags[0].Value = "changed"
+		assert.Equal(t, "1", builder.Build()[0].Value)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		assert.Empty(t, NewTags().Build())
+	})
+}
+
+func TestFindTag(t *testing.T) {
+	tags := []tag.Tag{{Name: "Action", Value: "Debit-Notice"}, {Name: "Memo", Value: ""}, {Name: "Action", Value: "Ignored"}}
+
+	v, ok := FindTag(tags, "Action")
+	assert.True(t, ok)
+	assert.Equal(t, "Debit-Notice", v)
+
+	v, ok = FindTag(tags, "Memo")
+	assert.True(t, ok)
+	assert.Equal(t, "", v)
+
+	_, ok = FindTag(tags, "action")
+	assert.False(t, ok)
+
+	v, ok = FindTagFold(tags, "action")
+	assert.True(t, ok)
+	assert.Equal(t, "Debit-Notice", v)
+
+	_, ok = FindTagFold(nil, "Action")
+	assert.False(t, ok)
+}
+
+func TestValidateTags(t *testing.T) {
+	assert.NoError(t, validateTags(NewTags().Action("Eval").Build()))
+
+	tags := make([]tag.Tag, 129)
+	for i := range tags {
+		tags[i] = tag.Tag{Name: "Name", Value: "value"}
+	}
+	assert.ErrorIs(t, validateTags(tags), ErrTooManyTags)
+
+	err := validateTags([]tag.Tag{{Name: strings.Repeat("n", 1025), Value: "value"}})
+	assert.ErrorIs(t, err, ErrTagTooLarge)
+	assert.ErrorContains(t, err, "name of tag")
+
+	err = validateTags([]tag.Tag{{Name: "Memo", Value: strings.Repeat("v", 3073)}})
+	assert.ErrorIs(t, err, ErrTagTooLarge)
+	assert.ErrorContains(t, err, `value of tag "Memo" is 3073 bytes`)
+
+	t.Run("BeforeSending", func(t *testing.T) {
+		ao, err := New(WithMUURL("http://127.0.0.1:0"))
+		assert.NoError(t, err)
+		s := setupSigner(t)
+
+		_, err = ao.SendMessage(testProcess, "data", &[]tag.Tag{{Name: "Memo", Value: strings.Repeat("v", 3073)}}, "", s)
+		assert.ErrorIs(t, err, ErrTagTooLarge)
+		// The protocol tags count towards the limit.
+		_, err = ao.SpawnProcess(testModule, nil, tags[:125], s)
+		assert.ErrorIs(t, err, ErrTooManyTags)
+	})
+}
+
+func TestProtocolTags(t *testing.T) {
+	count := func(tags []tag.Tag, name string) int {
+		n := 0
+		for _, t := range tags {
+			if t.Name == name {
+				n++
+			}
+		}
+		return n
+	}
+	s := setupSigner(t)
+
+	t.Run("Added", func(t *testing.T) {
+		ao, err := New()
+		assert.NoError(t, err)
+		raw, err := ao.SignMessage(testProcess, nil, nil, "", s)
+		assert.NoError(t, err)
+		item, err := data_item.Decode(raw)
+		assert.NoError(t, err)
+		assert.Contains(t, *item.Tags, tag.Tag{Name: "Data-Protocol", Value: "ao"})
+		assert.Contains(t, *item.Tags, tag.Tag{Name: "Variant", Value: DefaultVariant})
+		assert.Contains(t, *item.Tags, tag.Tag{Name: "Type", Value: "Message"})
+	})
+
+	t.Run("CallerWins", func(t *testing.T) {
+		ao, err := New()
+		assert.NoError(t, err)
+		raw, err := ao.SignMessage(testProcess, nil, []tag.Tag{{Name: "Variant", Value: "ao.TN.2"}}, "", s)
+		assert.NoError(t, err)
+		item, err := data_item.Decode(raw)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count(*item.Tags, "Variant"))
+		assert.Contains(t, *item.Tags, tag.Tag{Name: "Variant", Value: "ao.TN.2"})
+
+		raw, err = ao.SignSpawn(testModule, nil, []tag.Tag{{Name: "Scheduler", Value: testProcess}}, s)
+		assert.NoError(t, err)
+		item, err = data_item.Decode(raw)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count(*item.Tags, "Scheduler"))
+		assert.Equal(t, 1, count(*item.Tags, "Data-Protocol"))
+	})
+
+	t.Run("WithVariant", func(t *testing.T) {
+		var dryRun Message
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&dryRun))
+			_, err := w.Write([]byte(`{"Messages": [], "Spawns": [], "Outputs": [], "GasUsed": 0}`))
+			assert.NoError(t, err)
+		}))
+		defer srv.Close()
+
+		ao, err := New(WithCUURL(srv.URL), WithVariant("ao.TN.2"))
+		assert.NoError(t, err)
+		raw, err := ao.SignSpawn(testModule, nil, nil, s)
+		assert.NoError(t, err)
+		item, err := data_item.Decode(raw)
+		assert.NoError(t, err)
+		assert.Contains(t, *item.Tags, tag.Tag{Name: "Variant", Value: "ao.TN.2"})
+
+		_, err = ao.DryRun(Message{Target: testProcess})
+		assert.NoError(t, err)
+		assert.Contains(t, *dryRun.Tags, tag.Tag{Name: "Variant", Value: "ao.TN.2"})
+		assert.Equal(t, 1, count(*dryRun.Tags, "Type"))
+	})
+}
+
+func TestSDKTags(t *testing.T) {
+	s := setupSigner(t)
+	decode := func(raw []byte, err error) []tag.Tag {
+		assert.NoError(t, err)
+		item, err := data_item.Decode(raw)
+		assert.NoError(t, err)
+		return *item.Tags
+	}
+
+	ao, err := New()
+	assert.NoError(t, err)
+	tags := decode(ao.SignMessage(testProcess, nil, nil, "", s))
+	assert.Contains(t, tags, tag.Tag{Name: "SDK", Value: SDK})
+	assert.Contains(t, tags, tag.Tag{Name: "SDK-Version", Value: Version})
+	tags = decode(ao.SignSpawn(testModule, nil, nil, s))
+	assert.Contains(t, tags, tag.Tag{Name: "SDK-Version", Value: Version})
+
+	tags = decode(ao.SignMessage(testProcess, nil, []tag.Tag{{Name: "SDK", Value: "mine"}}, "", s))
+	assert.Contains(t, tags, tag.Tag{Name: "SDK", Value: "mine"})
+	assert.NotContains(t, tags, tag.Tag{Name: "SDK", Value: SDK})
+	tags = decode(ao.SignSpawn(testModule, nil, []tag.Tag{{Name: "SDK-Version", Value: "9"}}, s))
+	assert.NotContains(t, tags, tag.Tag{Name: "SDK-Version", Value: Version})
+
+	ao, err = New(WithoutSDKTags())
+	assert.NoError(t, err)
+	for _, tg := range decode(ao.SignMessage(testProcess, nil, nil, "", s)) {
+		assert.NotContains(t, tg.Name, "SDK")
+	}
+	for _, tg := range decode(ao.SignSpawn(testModule, nil, nil, s)) {
+		assert.NotContains(t, tg.Name, "SDK")
+	}
+}
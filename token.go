@@ -0,0 +1,95 @@
+package aogo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/liteseed/goar/signer"
+	"github.com/liteseed/goar/tag"
+)
+
+// Info dry runs the Info action of process and flattens the tags of the reply into a map.
+// Processes that reply with a JSON object instead have its fields merged in, with tags taking precedence.
+func (ao *AO) Info(process string) (map[string]string, error) {
+	res, err := ao.DryRunAs(process, "", "Info", nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(res.Messages) == 0 {
+		return nil, fmt.Errorf("info: %w", ErrEmptyResult)
+	}
+	reply := res.Messages[0]
+
+	info := make(map[string]string)
+	if fields, ok := jsonObject(reply.Data); ok {
+		for k, v := range fields {
+			info[k] = stringify(v)
+		}
+	}
+	for _, t := range reply.Tags {
+		info[t.Name] = t.Value
+	}
+	return info, nil
+}
+
+// Balance dry runs the Balance action of the token process for address and returns the balance as
+// reported, without converting it, since balances can exceed int64.
+// The Balance tag of the reply is preferred over its data.
+func (ao *AO) Balance(process string, address string) (string, error) {
+	res, err := ao.DryRunAs(process, "", "Balance", []tag.Tag{
+		{Name: "Target", Value: address},
+		{Name: "Recipient", Value: address},
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(res.Messages) == 0 {
+		return "", fmt.Errorf("balance: %w", ErrEmptyResult)
+	}
+	reply := res.Messages[0]
+	if balance, ok := reply.Tag("Balance"); ok {
+		return balance, nil
+	}
+	if reply.Data == "" {
+		return "", fmt.Errorf("balance: %w", ErrEmptyResult)
+	}
+	return reply.Data, nil
+}
+
+// Transfer sends quantity of the token process to recipient and returns the ID of the Transfer message.
+// Extra tags, such as X- memo tags, are forwarded with it. quantity must be a positive integer in the
+// token's smallest unit; anything else fails with ErrInvalidQuantity before a message is signed.
+func (ao *AO) Transfer(process string, recipient string, quantity string, s *signer.Signer, extra ...tag.Tag) (string, error) {
+	if !isPositiveInteger(quantity) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidQuantity, quantity)
+	}
+	tags := append([]tag.Tag{
+		{Name: "Action", Value: "Transfer"},
+		{Name: "Recipient", Value: recipient},
+		{Name: "Quantity", Value: quantity},
+	}, extra...)
+	return ao.SendMessage(process, "", &tags, "", s)
+}
+
+func isPositiveInteger(s string) bool {
+	nonZero := false
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+		nonZero = nonZero || c != '0'
+	}
+	return nonZero
+}
+
+// jsonObject decodes data as a JSON object, keeping numbers exact.
+func jsonObject(data string) (map[string]any, bool) {
+	d := json.NewDecoder(bytes.NewReader([]byte(data)))
+	d.UseNumber()
+	var fields map[string]any
+	if d.Decode(&fields) != nil || fields == nil {
+		return nil, false
+	}
+	return fields, true
+}
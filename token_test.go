@@ -0,0 +1,114 @@
+package aogo
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/liteseed/goar/signer"
+	"github.com/liteseed/goar/tag"
+	"github.com/liteseed/goar/transaction/data_item"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInfo(t *testing.T) {
+	body := ""
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var m Message
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&m))
+		assert.Contains(t, *m.Tags, tag.Tag{Name: "Action", Value: "Info"})
+		_, err := w.Write([]byte(body))
+		assert.NoError(t, err)
+	}))
+	defer srv.Close()
+	ao := &AO{cu: newCU(srv.URL)}
+
+	t.Run("Tags", func(t *testing.T) {
+		body = `{"Messages": [{"Target": "0000000000000000000000000000000000000000000", "Tags": [{"name": "Name", "value": "Token"}, {"name": "Ticker", "value": "TKN"}], "Data": ""}]}`
+		info, err := ao.Info("process")
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"Name": "Token", "Ticker": "TKN"}, info)
+	})
+	t.Run("JSON", func(t *testing.T) {
+		body = `{"Messages": [{"Tags": [{"name": "Name", "value": "Token"}], "Data": {"Name": "Ignored", "Denomination": 12, "Supply": 123456789012345678901234}}]}`
+		info, err := ao.Info("process")
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"Name": "Token", "Denomination": "12", "Supply": "123456789012345678901234"}, info)
+	})
+	t.Run("NoReply", func(t *testing.T) {
+		body = `{"Messages": []}`
+		_, err := ao.Info("process")
+		assert.ErrorIs(t, err, ErrEmptyResult)
+	})
+}
+
+func TestBalance(t *testing.T) {
+	body := ""
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var m Message
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&m))
+		assert.Contains(t, *m.Tags, tag.Tag{Name: "Action", Value: "Balance"})
+		assert.Contains(t, *m.Tags, tag.Tag{Name: "Target", Value: "address"})
+		assert.Contains(t, *m.Tags, tag.Tag{Name: "Recipient", Value: "address"})
+		_, err := w.Write([]byte(body))
+		assert.NoError(t, err)
+	}))
+	defer srv.Close()
+	ao := &AO{cu: newCU(srv.URL)}
+
+	t.Run("Tag", func(t *testing.T) {
+		body = `{"Messages": [{"Tags": [{"name": "Balance", "value": "123456789012345678901234"}], "Data": "ignored"}]}`
+		balance, err := ao.Balance("process", "address")
+		assert.NoError(t, err)
+		assert.Equal(t, "123456789012345678901234", balance)
+	})
+	t.Run("Data", func(t *testing.T) {
+		body = `{"Messages": [{"Tags": [], "Data": 123456789012345678901234}]}`
+		balance, err := ao.Balance("process", "address")
+		assert.NoError(t, err)
+		assert.Equal(t, "123456789012345678901234", balance)
+	})
+	t.Run("Empty", func(t *testing.T) {
+		body = `{"Messages": [{"Tags": [], "Data": ""}]}`
+		_, err := ao.Balance("process", "address")
+		assert.ErrorIs(t, err, ErrEmptyResult)
+	})
+}
+
+func TestTransfer(t *testing.T) {
+	process := testProcess
+	var got *data_item.DataItem
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		got, err = data_item.Decode(b)
+		assert.NoError(t, err)
+		_, err = w.Write([]byte(`{"id": "mockMessageID"}`))
+		assert.NoError(t, err)
+	}))
+	defer srv.Close()
+	ao := &AO{mu: newMU(srv.URL)}
+	s, err := signer.FromPath("./keys/wallet.json")
+	assert.NoError(t, err)
+
+	t.Run("Success", func(t *testing.T) {
+		id, err := ao.Transfer(process, "recipient", "1000000000000000000000", s, tag.Tag{Name: "X-Memo", Value: "thanks"})
+		assert.NoError(t, err)
+		assert.Equal(t, "mockMessageID", id)
+		assert.Equal(t, process, got.Target)
+		assert.Contains(t, *got.Tags, tag.Tag{Name: "Action", Value: "Transfer"})
+		assert.Contains(t, *got.Tags, tag.Tag{Name: "Recipient", Value: "recipient"})
+		assert.Contains(t, *got.Tags, tag.Tag{Name: "Quantity", Value: "1000000000000000000000"})
+		assert.Contains(t, *got.Tags, tag.Tag{Name: "X-Memo", Value: "thanks"})
+	})
+	t.Run("InvalidQuantity", func(t *testing.T) {
+		got = nil
+		for _, q := range []string{"", "0", "000", "-1", "1.5", "1e3", " 1"} {
+			_, err := ao.Transfer(process, "recipient", q, s)
+			assert.ErrorIs(t, err, ErrInvalidQuantity, q)
+		}
+		assert.Nil(t, got)
+	})
+}
@@ -0,0 +1,41 @@
+package aogo
+
+import "context"
+
+// Tracer starts a span around every SpawnProcess, SendMessage, LoadResult and DryRun call, including the calls
+// made by helpers built on them. The aootel package implements it with OpenTelemetry.
+type Tracer interface {
+	// Start starts a span for operation on unit, nested under any span in ctx. process is empty for spawns.
+	Start(ctx context.Context, operation string, unit Unit, process string) (context.Context, Span)
+}
+
+// Span is a span started by a Tracer.
+type Span interface {
+	// SetStatusCode records the HTTP status of a request made within the span, once per attempt.
+	SetStatusCode(code int)
+	// End ends the span, recording err if the call failed.
+	End(err error)
+}
+
+type spanKey struct{}
+
+type noopSpan struct{}
+
+func (noopSpan) SetStatusCode(int) {}
+func (noopSpan) End(error)         {}
+
+// startSpan starts a span with the tracer of ao, if any, and stores it in the returned context so
+// requests made under it can report their status.
+func (ao *AO) startSpan(ctx context.Context, operation string, unit Unit, process string) (context.Context, Span) {
+	if ao.tracer == nil {
+		return ctx, noopSpan{}
+	}
+	ctx, span := ao.tracer.Start(ctx, operation, unit, process)
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// spanFromContext returns the span started by startSpan for ctx, if any.
+func spanFromContext(ctx context.Context) (Span, bool) {
+	span, ok := ctx.Value(spanKey{}).(Span)
+	return span, ok
+}
@@ -0,0 +1,82 @@
+package aogo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordedSpan struct {
+	operation string
+	unit      Unit
+	process   string
+	parent    string
+	codes     []int
+	err       error
+	ended     bool
+}
+
+type recordingTracer struct {
+	mu    sync.Mutex
+	spans []*recordedSpan
+}
+
+type parentKey struct{}
+
+func (t *recordingTracer) Start(ctx context.Context, operation string, unit Unit, process string) (context.Context, Span) {
+	parent, _ := ctx.Value(parentKey{}).(string)
+	span := &recordedSpan{operation: operation, unit: unit, process: process, parent: parent}
+	t.mu.Lock()
+	t.spans = append(t.spans, span)
+	t.mu.Unlock()
+	return ctx, span
+}
+
+func (s *recordedSpan) SetStatusCode(code int) { s.codes = append(s.codes, code) }
+func (s *recordedSpan) End(err error)          { s.err, s.ended = err, true }
+
+func TestWithTracer(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.Method == http.MethodGet && calls == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		if r.Method == http.MethodPost && r.URL.Path == "/dry-run" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, err := w.Write([]byte(`{"id": "mockID", "Messages": []}`))
+		assert.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	tracer := &recordingTracer{}
+	ao, err := New(WithCUURL(srv.URL), WithMUURL(srv.URL), WithTracer(tracer), WithCURetry(RetryPolicy{MaxAttempts: 2}))
+	assert.NoError(t, err)
+	s := setupSigner(t)
+	ctx := context.WithValue(context.Background(), parentKey{}, "request")
+
+	_, err = ao.LoadResultContext(ctx, testProcess, testMessage)
+	assert.NoError(t, err)
+	_, err = ao.SendMessageContext(ctx, testProcess, "data", nil, "", s)
+	assert.NoError(t, err)
+	_, err = ao.SpawnProcessResultContext(ctx, testModule, nil, nil, s)
+	assert.NoError(t, err)
+	_, err = ao.DryRunContext(ctx, Message{Target: testProcess})
+	assert.Error(t, err)
+
+	assert.Len(t, tracer.spans, 4)
+	load, send, spawn, dryRun := tracer.spans[0], tracer.spans[1], tracer.spans[2], tracer.spans[3]
+	assert.Equal(t, &recordedSpan{operation: "LoadResult", unit: UnitCU, process: testProcess, parent: "request", codes: []int{502, 200}, ended: true}, load)
+	assert.Equal(t, &recordedSpan{operation: "SendMessage", unit: UnitMU, process: testProcess, parent: "request", codes: []int{200}, ended: true}, send)
+	assert.Equal(t, &recordedSpan{operation: "SpawnProcess", unit: UnitMU, parent: "request", codes: []int{200}, ended: true}, spawn)
+	assert.Equal(t, "DryRun", dryRun.operation)
+	assert.Equal(t, []int{400}, dryRun.codes)
+	assert.ErrorIs(t, dryRun.err, err)
+}
@@ -0,0 +1,58 @@
+package aogo
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+// Connection pool settings WithTransportTuning uses for the arguments left at zero. AO clients send many
+// requests to a handful of unit hosts, so unlike http.DefaultTransport, which keeps 2 idle connections per host,
+// they keep enough idle connections per host to serve concurrent requests without opening new ones.
+const (
+	DefaultMaxIdleConns        = 100
+	DefaultMaxIdleConnsPerHost = 32
+	DefaultIdleConnTimeout     = 90 * time.Second
+)
+
+// applyTransport gives every unit a copy of its client with a transport adjusted by ao.transport. Units sharing
+// a client keep sharing one transport, and with it one connection pool. Shared clients and transports,
+// including http.DefaultClient and http.DefaultTransport, are not modified.
+func (ao *AO) applyTransport() {
+	if len(ao.transport) == 0 || ao.err != nil {
+		return
+	}
+	clients := map[*http.Client]*http.Client{}
+	for _, client := range []**http.Client{&ao.cu.client, &ao.mu.client, &ao.su.client, &ao.gateway.client} {
+		c, ok := clients[*client]
+		if !ok {
+			var err error
+			c, err = configureClient(*client, ao.transport)
+			if err != nil {
+				ao.err = err
+				return
+			}
+			clients[*client] = c
+			ao.transports = append(ao.transports, c.Transport.(*http.Transport))
+		}
+		*client = c
+	}
+}
+
+func configureClient(client *http.Client, configure []func(*http.Transport)) (*http.Client, error) {
+	rt := client.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	t, ok := rt.(*http.Transport)
+	if !ok {
+		return nil, errors.New("transport options need the HTTP client to use an *http.Transport")
+	}
+	t = t.Clone()
+	for _, f := range configure {
+		f(t)
+	}
+	c := *client
+	c.Transport = t
+	return &c, nil
+}
@@ -0,0 +1,128 @@
+package aogo
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithProxy(t *testing.T) {
+	t.Run("Proxy", func(t *testing.T) {
+		var hosts []string
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// A forward proxy receives the absolute URL of the target.
+			assert.True(t, r.URL.IsAbs())
+			hosts = append(hosts, r.URL.Host)
+			_, err := w.Write([]byte(`{"Messages": [{"Data": "1"}], "Spawns": [], "Outputs": [], "Error": "", "GasUsed": 0}`))
+			assert.NoError(t, err)
+		}))
+		defer proxy.Close()
+
+		ao, err := New(WithCUURL("http://cu.example"), WithProxy(proxy.URL))
+		assert.NoError(t, err)
+		_, err = ao.LoadResult(testProcess, testMessage)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"cu.example"}, hosts)
+	})
+
+	t.Run("SharedTransport", func(t *testing.T) {
+		ao, err := New(WithProxy("http://proxy.example:3128"))
+		assert.NoError(t, err)
+		assert.NotSame(t, http.DefaultClient, ao.cu.client)
+		assert.Same(t, ao.cu.client, ao.mu.client)
+		assert.Same(t, ao.cu.client, ao.gateway.client)
+
+		u, err := ao.cu.client.Transport.(*http.Transport).Proxy(httptest.NewRequest("GET", "http://cu.example", nil))
+		assert.NoError(t, err)
+		assert.Equal(t, "proxy.example:3128", u.Host)
+	})
+
+	t.Run("HTTPClient", func(t *testing.T) {
+		transport := &http.Transport{}
+		client := &http.Client{Transport: transport}
+		ao, err := New(WithHTTPClient(client), WithProxy("http://proxy.example:3128"))
+		assert.NoError(t, err)
+		assert.NotSame(t, client, ao.cu.client)
+		assert.Nil(t, transport.Proxy)
+		assert.NotNil(t, ao.cu.client.Transport.(*http.Transport).Proxy)
+	})
+
+	t.Run("CustomTransport", func(t *testing.T) {
+		client := &http.Client{Transport: RoundTripperFunc(func(r *http.Request) (*http.Response, error) { return nil, nil })}
+		_, err := New(WithHTTPClient(client), WithProxy("http://proxy.example:3128"))
+		assert.ErrorContains(t, err, "*http.Transport")
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		_, err := New(WithProxy("proxy.example"))
+		assert.ErrorContains(t, err, "invalid proxy URL")
+		_, err = New(WithProxy("http://[::1"))
+		assert.ErrorContains(t, err, "invalid proxy URL")
+	})
+}
+
+func TestWithTLSConfig(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"Messages": [{"Data": "1"}], "Spawns": [], "Outputs": [], "Error": "", "GasUsed": 0}`))
+		assert.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	t.Run("UnknownAuthority", func(t *testing.T) {
+		ao, err := New(WithCUURL(srv.URL), WithCURetry(RetryPolicy{MaxAttempts: 1}))
+		assert.NoError(t, err)
+		_, err = ao.LoadResult(testProcess, testMessage)
+		assert.ErrorContains(t, err, "certificate")
+	})
+
+	t.Run("RootCAs", func(t *testing.T) {
+		pool := x509.NewCertPool()
+		pool.AddCert(srv.Certificate())
+		config := &tls.Config{RootCAs: pool}
+		ao, err := New(WithCUURL(srv.URL), WithTLSConfig(config))
+		assert.NoError(t, err)
+		config.RootCAs = nil
+
+		_, err = ao.LoadResult(testProcess, testMessage)
+		assert.NoError(t, err)
+	})
+
+	t.Run("InsecureSkipVerify", func(t *testing.T) {
+		ao, err := New(WithCUURL(srv.URL), WithTLSConfig(&tls.Config{InsecureSkipVerify: true}))
+		assert.NoError(t, err)
+		_, err = ao.LoadResult(testProcess, testMessage)
+		assert.NoError(t, err)
+	})
+
+	t.Run("WithProxy", func(t *testing.T) {
+		ao, err := New(WithProxy("http://proxy.example:3128"), WithTLSConfig(&tls.Config{ServerName: "cu.example"}))
+		assert.NoError(t, err)
+		transport := ao.cu.client.Transport.(*http.Transport)
+		assert.NotNil(t, transport.Proxy)
+		assert.Equal(t, "cu.example", transport.TLSClientConfig.ServerName)
+	})
+}
+
+func TestWithTransportTuning(t *testing.T) {
+	ao, err := New(WithTransportTuning(0, 0, 0))
+	assert.NoError(t, err)
+	transport := ao.cu.client.Transport.(*http.Transport)
+	assert.Equal(t, DefaultMaxIdleConns, transport.MaxIdleConns)
+	assert.Equal(t, DefaultMaxIdleConnsPerHost, transport.MaxIdleConnsPerHost)
+	assert.Equal(t, DefaultIdleConnTimeout, transport.IdleConnTimeout)
+	assert.Same(t, transport, ao.mu.client.Transport)
+	assert.NotEqual(t, DefaultMaxIdleConnsPerHost, http.DefaultTransport.(*http.Transport).MaxIdleConnsPerHost)
+
+	ao, err = New(WithTransportTuning(10, 5, time.Second), WithProxy("http://proxy.example:3128"))
+	assert.NoError(t, err)
+	transport = ao.su.client.Transport.(*http.Transport)
+	assert.Equal(t, 10, transport.MaxIdleConns)
+	assert.Equal(t, 5, transport.MaxIdleConnsPerHost)
+	assert.Equal(t, time.Second, transport.IdleConnTimeout)
+	assert.NotNil(t, transport.Proxy)
+}
@@ -0,0 +1,108 @@
+package aogo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrNotPushed reports an outbound message that the gateway does not know as pushed, either because the MU
+// has not pushed it yet or because the gateway has not indexed it.
+var ErrNotPushed = errors.New("outbound message not pushed")
+
+// MessageNode is a message in the tree built by Trace.
+type MessageNode struct {
+	// Process is the process the message was sent to.
+	Process string
+	// Message is the ID of the message, or empty if it could not be resolved.
+	Message string
+	// Action is the Action tag of the message, if it has one.
+	Action string
+	// Result is the result of the message, or nil if it could not be loaded.
+	Result *Response
+	// Err is the error that stopped the trace at this node, if any.
+	Err error
+	// Truncated reports that the outbound messages of Result were not followed because of the depth limit.
+	Truncated bool
+	// Children are the outbound messages of Result in the order the process sent them.
+	Children []*MessageNode
+}
+
+// Trace loads the result of message on process and follows its outbound messages to their own results,
+// building the tree of messages it caused. Outbound messages are matched through the gateway with the messages
+// the MU pushed for them, so recent pushes may still be missing and show up with ErrNotPushed.
+//
+// Messages deeper than maxDepth below the root are not loaded, and a message already in the tree is not
+// followed again, which bounds the walk even for processes messaging each other in a loop. Errors below the
+// root are recorded in the nodes; if the root result itself cannot be loaded, Trace returns its error too.
+func (ao *AO) Trace(process string, message string, maxDepth int) (*MessageNode, error) {
+	if err := validateID("process", process); err != nil {
+		return nil, err
+	}
+	if err := validateID("message", message); err != nil {
+		return nil, err
+	}
+	root := &MessageNode{Process: process, Message: message}
+	ao.traceNode(root, message, maxDepth, map[string]bool{message: true})
+	if root.Result == nil {
+		return root, root.Err
+	}
+	return root, nil
+}
+
+func (ao *AO) traceNode(n *MessageNode, root string, depth int, seen map[string]bool) {
+	n.Result, n.Err = ao.loadResult(context.Background(), n.Process, n.Message, nil)
+	if n.Err != nil || len(n.Result.Messages) == 0 {
+		return
+	}
+	if depth <= 0 {
+		n.Truncated = true
+		return
+	}
+
+	pushedFor := []string{n.Message}
+	if root != n.Message {
+		pushedFor = append(pushedFor, root)
+	}
+	pushed, err := ao.gateway.pushedMessages(n.Process, pushedFor)
+	if err != nil {
+		n.Err = fmt.Errorf("resolve outbound messages: %w", err)
+	}
+	used := make([]bool, len(pushed))
+	for _, m := range n.Result.Messages {
+		action, _ := m.Tag("Action")
+		child := &MessageNode{Process: m.Target, Action: action}
+		n.Children = append(n.Children, child)
+		if err != nil {
+			child.Err = err
+			continue
+		}
+		child.Message = matchPushed(m, pushed, used)
+		switch {
+		case child.Message == "":
+			child.Err = ErrNotPushed
+		case seen[child.Message]:
+			child.Err = fmt.Errorf("message %s is already in the trace", child.Message)
+		default:
+			seen[child.Message] = true
+			ao.traceNode(child, root, depth-1, seen)
+		}
+	}
+}
+
+// matchPushed returns the ID of the first unused pushed message sent to the target of m, with the same Reference
+// tag if m has one, and marks it used. It returns "" if there is none.
+func matchPushed(m ResultMessage, pushed []Transaction, used []bool) string {
+	reference, hasReference := m.Tag("Reference")
+	for i, tx := range pushed {
+		if used[i] || tx.Recipient != m.Target {
+			continue
+		}
+		if r, ok := FindTag(tx.Tags, "Reference"); hasReference && ok && r != reference {
+			continue
+		}
+		used[i] = true
+		return tx.ID
+	}
+	return ""
+}
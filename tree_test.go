@@ -0,0 +1,113 @@
+package aogo
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrace(t *testing.T) {
+	procB := strings.Repeat("b", 43)
+	procC := strings.Repeat("c", 43)
+	msgB := strings.Repeat("B", 43)
+	msgC := strings.Repeat("C", 43)
+
+	results := map[string]string{
+		testMessage: `{"Messages": [
+			{"Target": "` + procB + `", "Tags": [{"name": "Action", "value": "Credit"}, {"name": "Reference", "value": "1"}]},
+			{"Target": "` + procC + `", "Tags": [{"name": "Action", "value": "Notice"}]},
+			{"Target": "` + procB + `", "Tags": [{"name": "Action", "value": "Lost"}, {"name": "Reference", "value": "2"}]}
+		]}`,
+		msgB: `{"Messages": [{"Target": "` + testProcess + `", "Tags": [{"name": "Action", "value": "Loop"}]}]}`,
+		msgC: `{"Messages": [], "Error": "boom"}`,
+	}
+	pushed := map[string]string{
+		testProcess: `[
+			{"node": {"id": "` + msgC + `", "recipient": "` + procC + `"}},
+			{"node": {"id": "` + msgB + `", "recipient": "` + procB + `", "tags": [{"name": "Reference", "value": "1"}]}}
+		]`,
+		procB: `[{"node": {"id": "` + testMessage + `", "recipient": "` + testProcess + `"}}]`,
+	}
+	var queries int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/graphql" {
+			queries++
+			var req graphQLRequest
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			process := req.Variables["process"].([]any)[0].(string)
+			pushedFor := req.Variables["pushedFor"].([]any)
+			assert.Contains(t, pushedFor, testMessage)
+			_, err := w.Write([]byte(`{"data": {"transactions": {"edges": ` + pushed[process] + `}}}`))
+			assert.NoError(t, err)
+			return
+		}
+		res, ok := results[strings.TrimPrefix(r.URL.Path, "/result/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, err := w.Write([]byte(res))
+		assert.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	ao, err := New(WithCUURL(srv.URL), WithGatewayURL(srv.URL))
+	assert.NoError(t, err)
+
+	t.Run("Tree", func(t *testing.T) {
+		root, err := ao.Trace(testProcess, testMessage, 5)
+		assert.NoError(t, err)
+		assert.Equal(t, testMessage, root.Message)
+		assert.NotNil(t, root.Result)
+		assert.Len(t, root.Children, 3)
+
+		b := root.Children[0]
+		assert.Equal(t, procB, b.Process)
+		assert.Equal(t, msgB, b.Message)
+		assert.Equal(t, "Credit", b.Action)
+		assert.NoError(t, b.Err)
+		assert.Len(t, b.Children, 1)
+		assert.Equal(t, "Loop", b.Children[0].Action)
+		assert.ErrorContains(t, b.Children[0].Err, "already in the trace")
+
+		c := root.Children[1]
+		assert.Equal(t, msgC, c.Message)
+		assert.True(t, IsProcessError(c.Err))
+		assert.Empty(t, c.Children)
+
+		lost := root.Children[2]
+		assert.Equal(t, "Lost", lost.Action)
+		assert.Empty(t, lost.Message)
+		assert.ErrorIs(t, lost.Err, ErrNotPushed)
+	})
+
+	t.Run("MaxDepth", func(t *testing.T) {
+		queries = 0
+		root, err := ao.Trace(testProcess, testMessage, 0)
+		assert.NoError(t, err)
+		assert.True(t, root.Truncated)
+		assert.Empty(t, root.Children)
+		assert.Zero(t, queries)
+
+		root, err = ao.Trace(testProcess, testMessage, 1)
+		assert.NoError(t, err)
+		assert.True(t, root.Children[0].Truncated)
+		assert.Empty(t, root.Children[0].Children)
+	})
+
+	t.Run("RootNotFound", func(t *testing.T) {
+		root, err := ao.Trace(testProcess, testModule, 5)
+		assert.ErrorIs(t, err, ErrProcessNotFound)
+		assert.Equal(t, testModule, root.Message)
+		assert.Nil(t, root.Result)
+	})
+
+	t.Run("InvalidID", func(t *testing.T) {
+		_, err := ao.Trace("short", testMessage, 5)
+		assert.ErrorIs(t, err, ErrInvalidID)
+	})
+}
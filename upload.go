@@ -0,0 +1,180 @@
+package aogo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/liteseed/goar/signer"
+	"github.com/liteseed/goar/tag"
+	"github.com/liteseed/goar/transaction"
+)
+
+// Upload is an Arweave transaction being uploaded to the gateway by UploadData: its header is posted to /tx and
+// its data, split into chunks of up to 256 KiB, to /chunk. It records the chunks the gateway has accepted, so an
+// upload that failed part way is finished with ResumeUpload instead of being started over.
+type Upload struct {
+	// ID is the ID of the transaction, by which its data can be referenced, for example from an On-Boot tag.
+	ID string
+	// Size is the size of the data in bytes.
+	Size int
+	// Chunks is the number of chunks of the data.
+	Chunks int
+	// Uploaded is the number of chunks the gateway has accepted. Chunks are uploaded in order.
+	Uploaded int
+
+	tx     *transaction.Transaction
+	data   []byte
+	posted bool
+}
+
+// UploadError is returned by SpawnProcess when uploading the data of the process, see WithSpawnDataUpload,
+// fails. Once ResumeUpload has finished Upload, the process can be spawned with an On-Boot tag set to its ID.
+type UploadError struct {
+	// Upload is the interrupted upload.
+	Upload *Upload
+	// Err is the error that interrupted it.
+	Err error
+}
+
+func (e *UploadError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *UploadError) Unwrap() error {
+	return e.Err
+}
+
+// Done reports whether the header and every chunk of u have been accepted by the gateway.
+func (u *Upload) Done() bool {
+	return u.posted && u.Uploaded == u.Chunks
+}
+
+// UploadData signs data and tags as an Arweave transaction with s, or the default signer when s is nil, and
+// uploads it to the gateway in chunks, for data too large for a data item sent through the MU. The wallet of s
+// pays the storage fee the gateway quotes, so it must hold AR.
+//
+// Each request is retried according to the gateway retry policy. If the upload still fails once the transaction
+// is signed, the returned Upload is not nil and records how far it got: pass it to ResumeUpload to upload the
+// remaining chunks. Uploads report their progress in bytes of data to the ProgressFunc set with
+// WithUploadProgress.
+func (ao *AO) UploadData(data []byte, tags []tag.Tag, s *signer.Signer) (*Upload, error) {
+	return ao.UploadDataContext(context.Background(), data, tags, s)
+}
+
+// UploadDataContext is like UploadData but aborts when ctx is done and traces the call under ctx.
+func (ao *AO) UploadDataContext(ctx context.Context, data []byte, tags []tag.Tag, s *signer.Signer) (u *Upload, err error) {
+	ctx, span := ao.startSpan(ctx, "UploadData", UnitGateway, "")
+	defer func() { span.End(err) }()
+	s = ao.signerOr(s)
+	if s == nil || s.PrivateKey == nil {
+		return nil, fmt.Errorf("%w: signer is required", ErrInvalidSigner)
+	}
+	u, err = ao.gateway.newUpload(ctx, data, tags, s)
+	if err != nil {
+		return nil, fmt.Errorf("upload: %w", err)
+	}
+	return u, ao.gateway.upload(ctx, u)
+}
+
+// ResumeUpload uploads what UploadData could not, starting from the first chunk the gateway has not accepted.
+// It does nothing once u is done.
+func (ao *AO) ResumeUpload(u *Upload) error {
+	return ao.ResumeUploadContext(context.Background(), u)
+}
+
+// ResumeUploadContext is like ResumeUpload but aborts when ctx is done and traces the call under ctx.
+func (ao *AO) ResumeUploadContext(ctx context.Context, u *Upload) (err error) {
+	ctx, span := ao.startSpan(ctx, "ResumeUpload", UnitGateway, "")
+	defer func() { span.End(err) }()
+	return ao.gateway.upload(ctx, u)
+}
+
+// uploadSpawnData uploads the data of a process to spawn with UploadData and returns the tags that make the
+// process load it on boot in place of data.
+func (ao *AO) uploadSpawnData(ctx context.Context, data []byte, tags []tag.Tag, s *signer.Signer) ([]tag.Tag, error) {
+	if _, ok := FindTag(tags, "On-Boot"); ok {
+		return nil, fmt.Errorf("spawn data of %d bytes must be uploaded but tags already set On-Boot", len(data))
+	}
+	u, err := ao.UploadDataContext(ctx, data, nil, s)
+	if u != nil && err != nil {
+		return nil, &UploadError{Upload: u, Err: err}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]tag.Tag{}, tags...), tag.Tag{Name: "On-Boot", Value: u.ID}), nil
+}
+
+// newUpload signs data as a transaction anchored and priced by the gateway.
+func (g *Gateway) newUpload(ctx context.Context, data []byte, tags []tag.Tag, s *signer.Signer) (*Upload, error) {
+	// transaction.New encodes the tags in place, so it is given a copy.
+	txTags := append([]tag.Tag{}, tags...)
+	tx := transaction.New(data, "", "", &txTags)
+	tx.Owner = s.Owner()
+	anchor, err := g.text(ctx, "/tx_anchor")
+	if err != nil {
+		return nil, fmt.Errorf("anchor: %w", err)
+	}
+	tx.LastTx = anchor
+	reward, err := g.text(ctx, fmt.Sprintf("/price/%d", len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("price: %w", err)
+	}
+	tx.Reward = reward
+	if err := tx.Sign(s); err != nil {
+		return nil, fmt.Errorf("sign: %w", err)
+	}
+	// The data goes to /chunk, so the header is posted without it.
+	tx.Data = ""
+	return &Upload{ID: tx.ID, Size: len(data), Chunks: len(tx.ChunkData.Chunks), tx: tx, data: data}, nil
+}
+
+// upload posts the header of u unless the gateway has accepted it already, then the chunks it has not accepted.
+func (g *Gateway) upload(ctx context.Context, u *Upload) error {
+	if !u.posted {
+		header, err := json.Marshal(u.tx)
+		if err != nil {
+			return err
+		}
+		if _, err := g.post(ctx, "/tx", header); err != nil {
+			return fmt.Errorf("upload %s: post transaction: %w", u.ID, err)
+		}
+		u.posted = true
+	}
+	progress, _ := ctx.Value(progressKey{}).(ProgressFunc)
+	for u.Uploaded < u.Chunks {
+		chunk, err := u.tx.GetChunk(u.Uploaded, u.data)
+		if err != nil {
+			return err
+		}
+		b, err := json.Marshal(chunk)
+		if err != nil {
+			return err
+		}
+		if _, err := g.post(ctx, "/chunk", b); err != nil {
+			return fmt.Errorf("upload %s: chunk %d of %d: %w", u.ID, u.Uploaded+1, u.Chunks, err)
+		}
+		u.Uploaded++
+		if progress != nil {
+			progress(int64(u.tx.ChunkData.Chunks[u.Uploaded-1].MaxByteRange), int64(u.Size))
+		}
+	}
+	return nil
+}
+
+// text sends a GET request for path to the gateway and returns the response body as a string.
+func (g *Gateway) text(ctx context.Context, path string) (string, error) {
+	resp, cancel, err := g.get(ctx, path, "text/plain")
+	if err != nil {
+		return "", err
+	}
+	defer cancel()
+	defer resp.Body.Close()
+	b, err := readBody(resp.Body, g.maxResponseBytes)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(b)), nil
+}
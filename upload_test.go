@@ -0,0 +1,168 @@
+package aogo
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/liteseed/goar/tag"
+	"github.com/liteseed/goar/transaction"
+	"github.com/liteseed/goar/transaction/data_item"
+	"github.com/stretchr/testify/assert"
+)
+
+// arweaveNode is a gateway accepting transactions and their chunks. failChunk, if set, rejects the chunk at that
+// offset once.
+type arweaveNode struct {
+	mu        sync.Mutex
+	txs       []transaction.Transaction
+	chunks    map[int][]byte
+	failChunk int
+	spawned   []*data_item.DataItem
+}
+
+func (n *arweaveNode) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	b, _ := io.ReadAll(r.Body)
+	switch {
+	case r.URL.Path == "/tx_anchor":
+		_, _ = w.Write([]byte(strings.Repeat("A", 64)))
+	case strings.HasPrefix(r.URL.Path, "/price/"):
+		_, _ = w.Write([]byte("1000"))
+	case r.URL.Path == "/tx":
+		var tx transaction.Transaction
+		if err := json.Unmarshal(b, &tx); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		n.txs = append(n.txs, tx)
+	case r.URL.Path == "/chunk":
+		var chunk transaction.GetChunkResult
+		if err := json.Unmarshal(b, &chunk); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		offset, _ := strconv.Atoi(chunk.Offset)
+		if n.failChunk != 0 && offset == n.failChunk {
+			n.failChunk = 0
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error": "invalid_proof"}`))
+			return
+		}
+		data, _ := base64.RawURLEncoding.DecodeString(chunk.Chunk)
+		n.chunks[offset] = data
+	case r.URL.Path == "/":
+		item, err := data_item.Decode(b)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		n.spawned = append(n.spawned, item)
+		_, _ = w.Write([]byte(`{"id": "` + item.ID + `"}`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// data returns the chunks in offset order.
+func (n *arweaveNode) data() []byte {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	var b []byte
+	for len(n.chunks) > 0 {
+		end := -1
+		for offset := range n.chunks {
+			if end == -1 || offset < end {
+				end = offset
+			}
+		}
+		b = append(b, n.chunks[end]...)
+		delete(n.chunks, end)
+	}
+	return b
+}
+
+func TestUploadData(t *testing.T) {
+	node := &arweaveNode{chunks: map[int][]byte{}}
+	srv := httptest.NewServer(node)
+	defer srv.Close()
+	ao, err := New(WithGatewayURL(srv.URL), WithSigner(setupSigner(t)))
+	assert.NoError(t, err)
+
+	data := bytes.Repeat([]byte("0123456789abcdef"), 40000)
+	// The second chunk ends at the offset 512 KiB - 1.
+	node.failChunk = 2*256*1024 - 1
+	var sent int64
+	u, err := ao.UploadDataContext(WithUploadProgress(context.Background(), func(n int64, total int64) {
+		assert.Equal(t, int64(len(data)), total)
+		sent = n
+	}), data, []tag.Tag{{Name: "Content-Type", Value: "text/plain"}}, nil)
+	assert.ErrorContains(t, err, "chunk 2 of 3")
+	assert.ErrorContains(t, err, "invalid_proof")
+	assert.Equal(t, 3, u.Chunks)
+	assert.Equal(t, 1, u.Uploaded)
+	assert.False(t, u.Done())
+	assert.Equal(t, int64(256*1024), sent)
+
+	assert.NoError(t, ao.ResumeUpload(u))
+	assert.True(t, u.Done())
+	assert.NoError(t, ao.ResumeUpload(u))
+	assert.Len(t, node.txs, 1)
+
+	tx := node.txs[0]
+	assert.Equal(t, u.ID, tx.ID)
+	assert.Empty(t, tx.Data)
+	assert.Equal(t, strconv.Itoa(len(data)), tx.DataSize)
+	assert.Equal(t, "1000", tx.Reward)
+	got := node.data()
+	assert.Equal(t, data, got)
+	tx.Data = base64.RawURLEncoding.EncodeToString(got)
+	assert.NoError(t, tx.Verify())
+
+	_, err = (&AO{gateway: newGateway(srv.URL)}).UploadData(data, nil, nil)
+	assert.ErrorIs(t, err, ErrInvalidSigner)
+}
+
+func TestWithSpawnDataUpload(t *testing.T) {
+	node := &arweaveNode{chunks: map[int][]byte{}}
+	srv := httptest.NewServer(node)
+	defer srv.Close()
+	ao, err := New(WithGatewayURL(srv.URL), WithMUURL(srv.URL), WithSigner(setupSigner(t)), WithSpawnDataUpload(1024))
+	assert.NoError(t, err)
+
+	_, err = ao.SpawnProcess(testModule, []byte("small"), nil, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, node.txs)
+
+	data := bytes.Repeat([]byte("x"), 4096)
+	_, err = ao.SpawnProcess(testModule, data, []tag.Tag{{Name: "Name", Value: "big"}}, nil)
+	assert.NoError(t, err)
+	assert.Len(t, node.txs, 1)
+	assert.Equal(t, data, node.data())
+	spawned := node.spawned[1]
+	boot, ok := FindTag(*spawned.Tags, "On-Boot")
+	assert.True(t, ok)
+	assert.Equal(t, node.txs[0].ID, boot)
+	assert.Equal(t, base64.RawURLEncoding.EncodeToString([]byte("1984")), spawned.Data)
+
+	_, err = ao.SpawnProcess(testModule, data, []tag.Tag{{Name: "On-Boot", Value: "Data"}}, nil)
+	assert.ErrorContains(t, err, "already set On-Boot")
+
+	node.failChunk = len(data) - 1
+	_, err = ao.SpawnProcess(testModule, data, nil, nil)
+	var uploadErr *UploadError
+	assert.True(t, errors.As(err, &uploadErr))
+	assert.False(t, uploadErr.Upload.Done())
+	assert.NoError(t, ao.ResumeUpload(uploadErr.Upload))
+	assert.Len(t, node.spawned, 2)
+}
@@ -0,0 +1,138 @@
+package aogo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// DefaultPollInterval is how often WaitForResult polls the CU when WaitOptions.Interval is zero.
+const DefaultPollInterval = 500 * time.Millisecond
+
+// processPollInterval is how often WaitForProcess polls the gateway, which indexes new data items in seconds.
+var processPollInterval = 2 * time.Second
+
+// WaitOptions controls how WaitForResult polls the CU.
+type WaitOptions struct {
+	// Interval between two polls. Defaults to DefaultPollInterval.
+	Interval time.Duration
+	// MaxWait bounds the total wait. Zero relies on ctx alone.
+	MaxWait time.Duration
+}
+
+// WaitForResult polls the CU until the result of message has Messages, Spawns or Outputs, or reports an Error.
+// Failed polls are retried until ctx is done or MaxWait elapses, in which case the last failure is returned.
+func (ao *AO) WaitForResult(ctx context.Context, process string, message string, opts WaitOptions) (*Response, error) {
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultPollInterval
+	}
+	if opts.MaxWait > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.MaxWait)
+		defer cancel()
+	}
+
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+	var lastErr error
+	for {
+		res, err := ao.loadResult(ctx, process, message, nil)
+		switch {
+		case IsProcessError(err):
+			return nil, err
+		case err != nil:
+			if ctx.Err() == nil {
+				lastErr = err
+			}
+		case resultReady(res):
+			return res, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			if lastErr != nil {
+				return nil, fmt.Errorf("result not available: %w (last error: %v)", ctx.Err(), lastErr)
+			}
+			return nil, fmt.Errorf("result not available: %w", ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// resultReady reports whether res has Messages, Spawns or Outputs, which an evaluated message always has.
+func resultReady(res *Response) bool {
+	return len(res.Messages) > 0 || len(res.Spawns) > 0 || len(res.Outputs) > 0
+}
+
+// LoadResultLongPoll is like WaitForResult but first asks the CU, through the long-poll query parameter, to hold
+// the request for up to wait until the result is ready, which saves the latency and requests of polling. If the
+// CU answers before the result is ready, as CUs without long polling do, or fails, the client polls for what is
+// left of wait. The error of a result that is not ready after wait wraps context.DeadlineExceeded.
+func (ao *AO) LoadResultLongPoll(process string, message string, wait time.Duration) (*Response, error) {
+	return ao.LoadResultLongPollContext(context.Background(), process, message, wait)
+}
+
+// LoadResultLongPollContext is like LoadResultLongPoll but also gives up when ctx is done.
+func (ao *AO) LoadResultLongPollContext(ctx context.Context, process string, message string, wait time.Duration) (*Response, error) {
+	ctx, cancel := context.WithTimeout(ctx, wait)
+	defer cancel()
+	if ao.compute == nil {
+		params := url.Values{"long-poll": {strconv.FormatInt(wait.Milliseconds(), 10)}}
+		res, err := ao.loadResult(ctx, process, message, params)
+		switch {
+		case IsProcessError(err) || errors.Is(err, ErrInvalidID):
+			return nil, err
+		case err == nil && resultReady(res):
+			return res, nil
+		}
+	}
+	return ao.WaitForResult(ctx, process, message, WaitOptions{})
+}
+
+// WaitForProcess polls the gateway until it has indexed the spawn of process, after which the process can be
+// queried, and gives up after timeout. A zero timeout waits until the process is indexed.
+func (ao *AO) WaitForProcess(process string, timeout time.Duration) error {
+	return ao.WaitForProcessContext(context.Background(), process, timeout)
+}
+
+// WaitForProcessContext is like WaitForProcess but also gives up when ctx is done. The error of a wait that
+// gives up says how long it waited and wraps ctx.Err() or context.DeadlineExceeded.
+func (ao *AO) WaitForProcessContext(ctx context.Context, process string, timeout time.Duration) error {
+	if err := validateID("process", process); err != nil {
+		return err
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	ticker := time.NewTicker(processPollInterval)
+	defer ticker.Stop()
+	var lastErr error
+	for {
+		txs, err := ao.gateway.transactionsByID(ctx, process)
+		switch {
+		case err != nil:
+			if ctx.Err() == nil {
+				lastErr = err
+			}
+		case len(txs) > 0:
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			waited := time.Since(start).Round(time.Millisecond)
+			if lastErr != nil {
+				return fmt.Errorf("process %s not indexed after %s: %w (last error: %v)", process, waited, ctx.Err(), lastErr)
+			}
+			return fmt.Errorf("process %s not indexed after %s: %w", process, waited, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package aogo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWaitForResult(t *testing.T) {
+	t.Run("Ready", func(t *testing.T) {
+		var polls atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body := `{"Messages": [], "Spawns": [], "Outputs": [], "Error": "", "GasUsed": 0}`
+			if polls.Add(1) >= 3 {
+				body = `{"Messages": [{"Target": "target"}], "Spawns": [], "Outputs": [], "Error": "", "GasUsed": 10}`
+			}
+			_, err := w.Write([]byte(body))
+			assert.NoError(t, err)
+		}))
+		defer srv.Close()
+
+		ao := &AO{cu: newCU(srv.URL)}
+		res, err := ao.WaitForResult(context.Background(), testProcess, testMessage, WaitOptions{Interval: time.Millisecond})
+		assert.NoError(t, err)
+		assert.Equal(t, "target", res.Messages[0].Target)
+		assert.Equal(t, int32(3), polls.Load())
+	})
+
+	t.Run("ProcessError", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, err := w.Write([]byte(`{"Messages": [], "Spawns": [], "Outputs": [], "Error": "boom", "GasUsed": 0}`))
+			assert.NoError(t, err)
+		}))
+		defer srv.Close()
+
+		ao := &AO{cu: newCU(srv.URL)}
+		_, err := ao.WaitForResult(context.Background(), testProcess, testMessage, WaitOptions{Interval: time.Millisecond})
+		assert.ErrorContains(t, err, "boom")
+	})
+
+	t.Run("MaxWait", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer srv.Close()
+
+		ao := &AO{cu: newCU(srv.URL)}
+		_, err := ao.WaitForResult(context.Background(), testProcess, testMessage, WaitOptions{Interval: 5 * time.Millisecond, MaxWait: 30 * time.Millisecond})
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+		assert.ErrorContains(t, err, "404")
+	})
+
+	t.Run("Cancelled", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, err := w.Write([]byte(`{"Messages": [], "Spawns": [], "Outputs": [], "Error": "", "GasUsed": 0}`))
+			assert.NoError(t, err)
+		}))
+		defer srv.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		ao := &AO{cu: newCU(srv.URL)}
+		_, err := ao.WaitForResult(ctx, testProcess, testMessage, WaitOptions{})
+		assert.True(t, errors.Is(err, context.Canceled))
+	})
+}
+
+func TestWaitForProcess(t *testing.T) {
+	interval := processPollInterval
+	processPollInterval = time.Millisecond
+	defer func() { processPollInterval = interval }()
+
+	t.Run("Indexed", func(t *testing.T) {
+		var polls atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body := `{"data": {"transactions": {"edges": []}}}`
+			if polls.Add(1) >= 3 {
+				body = `{"data": {"transactions": {"edges": [{"node": {"id": "` + testProcess + `", "block": null}}]}}}`
+			}
+			_, err := w.Write([]byte(body))
+			assert.NoError(t, err)
+		}))
+		defer srv.Close()
+
+		ao := &AO{gateway: newGateway(srv.URL)}
+		assert.NoError(t, ao.WaitForProcess(testProcess, time.Second))
+		assert.Equal(t, int32(3), polls.Load())
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, err := w.Write([]byte(`{"data": {"transactions": {"edges": []}}}`))
+			assert.NoError(t, err)
+		}))
+		defer srv.Close()
+
+		ao := &AO{gateway: newGateway(srv.URL)}
+		err := ao.WaitForProcess(testProcess, 30*time.Millisecond)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+		assert.ErrorContains(t, err, "not indexed after")
+	})
+
+	t.Run("GatewayError", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer srv.Close()
+
+		ao := &AO{gateway: newGateway(srv.URL)}
+		err := ao.WaitForProcess(testProcess, 30*time.Millisecond)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+		assert.ErrorContains(t, err, "400")
+	})
+
+	t.Run("Cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		ao := &AO{gateway: newGateway("http://127.0.0.1:0")}
+		err := ao.WaitForProcessContext(ctx, testProcess, 0)
+		assert.True(t, errors.Is(err, context.Canceled))
+	})
+
+	t.Run("InvalidID", func(t *testing.T) {
+		ao := &AO{gateway: newGateway("http://127.0.0.1:0")}
+		assert.ErrorIs(t, ao.WaitForProcess("short", time.Second), ErrInvalidID)
+	})
+}
+
+func TestLoadResultLongPoll(t *testing.T) {
+	ready := `{"Messages": [], "Spawns": [], "Outputs": ["done"], "GasUsed": 0}`
+	empty := `{"Messages": [], "Spawns": [], "Outputs": [], "GasUsed": 0}`
+
+	t.Run("Held", func(t *testing.T) {
+		var requests atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			assert.Equal(t, "5000", r.URL.Query().Get("long-poll"))
+			time.Sleep(20 * time.Millisecond)
+			_, err := w.Write([]byte(ready))
+			assert.NoError(t, err)
+		}))
+		defer srv.Close()
+
+		ao := &AO{cu: newCU(srv.URL)}
+		res, err := ao.LoadResultLongPoll(testProcess, testMessage, 5*time.Second)
+		assert.NoError(t, err)
+		assert.Equal(t, []any{"done"}, res.Outputs)
+		assert.EqualValues(t, 1, requests.Load())
+	})
+
+	t.Run("Unsupported", func(t *testing.T) {
+		var requests atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body := empty
+			if requests.Add(1) >= 2 {
+				body = ready
+			}
+			_, err := w.Write([]byte(body))
+			assert.NoError(t, err)
+		}))
+		defer srv.Close()
+
+		ao := &AO{cu: newCU(srv.URL)}
+		res, err := ao.LoadResultLongPoll(testProcess, testMessage, 5*time.Second)
+		assert.NoError(t, err)
+		assert.Equal(t, []any{"done"}, res.Outputs)
+		assert.EqualValues(t, 2, requests.Load())
+	})
+
+	t.Run("NotReady", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, err := w.Write([]byte(empty))
+			assert.NoError(t, err)
+		}))
+		defer srv.Close()
+
+		ao := &AO{cu: newCU(srv.URL)}
+		_, err := ao.LoadResultLongPoll(testProcess, testMessage, 50*time.Millisecond)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}